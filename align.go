@@ -0,0 +1,129 @@
+package bonesay
+
+import "strings"
+
+// Alignment is the alignment of the text in the balloon.
+type Alignment int
+
+// Alignment values.
+const (
+	// AlignLeft aligns the text to the left. It is the default.
+	AlignLeft Alignment = iota
+	// AlignCenter centers the text.
+	AlignCenter
+	// AlignRight aligns the text to the right.
+	AlignRight
+	// AlignJustify stretches the spaces between words so that the text
+	// fills the balloon. The last line of each paragraph is aligned to
+	// the left.
+	AlignJustify
+)
+
+func (a Alignment) String() string {
+	switch a {
+	case AlignLeft:
+		return "left"
+	case AlignCenter:
+		return "center"
+	case AlignRight:
+		return "right"
+	case AlignJustify:
+		return "justify"
+	}
+	return "unknown"
+}
+
+// Align specifies the alignment of the text in the balloon.
+func Align(a Alignment) Option {
+	return func(c *Bone) error {
+		c.align = a
+		return nil
+	}
+}
+
+// Padding specifies the number of spaces between the text and the left and
+// right borders, and the number of empty lines above and below the text.
+// The default is Padding(1, 0).
+func Padding(horizontal, vertical uint) Option {
+	return func(c *Bone) error {
+		c.paddingX = int(horizontal)
+		c.paddingY = int(vertical)
+		return nil
+	}
+}
+
+// MinBalloonWidth specifies the minimum width of the text in the balloon.
+// The balloon is widened to it even if the text is shorter.
+func MinBalloonWidth(width uint) Option {
+	return func(c *Bone) error {
+		c.minBalloonWidth = int(width)
+		return nil
+	}
+}
+
+// padding writes the line which is aligned in maxWidth.
+func (bone *Bone) padding(w *lineWriter, line *line, maxWidth int) {
+	gap := maxWidth - line.runeWidth
+	if gap <= 0 {
+		w.WriteString(line.text)
+		w.WriteString(line.reset)
+		return
+	}
+	switch bone.align {
+	case AlignCenter:
+		left := gap / 2
+		writeSpaces(w, left)
+		w.WriteString(line.text)
+		w.WriteString(line.reset)
+		writeSpaces(w, gap-left)
+		return
+	case AlignRight:
+		writeSpaces(w, gap)
+		w.WriteString(line.text)
+		w.WriteString(line.reset)
+		return
+	case AlignJustify:
+		if !line.paragraphEnd && justify(w, line.text, gap) {
+			w.WriteString(line.reset)
+			return
+		}
+	}
+	w.WriteString(line.text)
+	w.WriteString(line.reset)
+	writeSpaces(w, gap)
+}
+
+// justify writes text whose spaces between words are stretched by gap.
+// It returns false without writing anything if text has only one word.
+func justify(w *lineWriter, text string, gap int) bool {
+	body := strings.TrimLeft(text, " ")
+	indent := text[:len(text)-len(body)]
+	words := strings.Fields(body)
+	if len(words) < 2 {
+		return false
+	}
+	spaces := gap + visibleWidth(body)
+	for _, word := range words {
+		spaces -= visibleWidth(word)
+	}
+	n := len(words) - 1
+	w.WriteString(indent)
+	for i, word := range words {
+		w.WriteString(word)
+		if i < n {
+			// The former gaps are wider by one.
+			s := spaces / n
+			if i < spaces%n {
+				s++
+			}
+			writeSpaces(w, s)
+		}
+	}
+	return true
+}
+
+func writeSpaces(w *lineWriter, n int) {
+	for i := 0; i < n; i++ {
+		w.WriteRune(' ')
+	}
+}
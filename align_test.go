@@ -0,0 +1,114 @@
+package bonesay
+
+import "testing"
+
+func TestAlign(t *testing.T) {
+	const phrase = "the quick brown fox jumps over the lazy dog\nend"
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "left",
+			opts: []Option{Align(AlignLeft)},
+			want: "" +
+				" _________________ \n" +
+				"/ the quick brown \\\n" +
+				"| fox jumps over  |\n" +
+				"| the lazy dog    |\n" +
+				"\\ end             /\n" +
+				" ----------------- \n",
+		},
+		{
+			name: "center",
+			opts: []Option{Align(AlignCenter)},
+			want: "" +
+				" _________________ \n" +
+				"/ the quick brown \\\n" +
+				"| fox jumps over  |\n" +
+				"|  the lazy dog   |\n" +
+				"\\       end       /\n" +
+				" ----------------- \n",
+		},
+		{
+			name: "right",
+			opts: []Option{Align(AlignRight)},
+			want: "" +
+				" _________________ \n" +
+				"/ the quick brown \\\n" +
+				"|  fox jumps over |\n" +
+				"|    the lazy dog |\n" +
+				"\\             end /\n" +
+				" ----------------- \n",
+		},
+		{
+			name: "justify",
+			opts: []Option{Align(AlignJustify)},
+			want: "" +
+				" _________________ \n" +
+				"/ the quick brown \\\n" +
+				"| fox  jumps over |\n" +
+				"| the lazy dog    |\n" +
+				"\\ end             /\n" +
+				" ----------------- \n",
+		},
+		{
+			name: "padding",
+			opts: []Option{Padding(3, 1)},
+			want: "" +
+				" _____________________ \n" +
+				"/                     \\\n" +
+				"|   the quick brown   |\n" +
+				"|   fox jumps over    |\n" +
+				"|   the lazy dog      |\n" +
+				"|   end               |\n" +
+				"\\                     /\n" +
+				" --------------------- \n",
+		},
+		{
+			name: "no padding",
+			opts: []Option{Padding(0, 0)},
+			want: "" +
+				" _______________ \n" +
+				"/the quick brown\\\n" +
+				"|fox jumps over |\n" +
+				"|the lazy dog   |\n" +
+				"\\end            /\n" +
+				" --------------- \n",
+		},
+		{
+			name: "min width",
+			opts: []Option{MinBalloonWidth(20), Align(AlignCenter)},
+			want: "" +
+				" ______________________ \n" +
+				"/   the quick brown    \\\n" +
+				"|    fox jumps over    |\n" +
+				"|     the lazy dog     |\n" +
+				"\\         end          /\n" +
+				" ---------------------- \n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bone, err := New(append([]Option{FromString(" $thoughts")}, tt.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := bone.Balloon(phrase); tt.want != got {
+				t.Errorf("want\n%s\ngot\n%s", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("one line with vertical padding", func(t *testing.T) {
+		bone, err := New(FromString(" $thoughts"), Padding(1, 1), MinBalloonWidth(4), Align(AlignRight))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := " ______ \n/      \\\n|   hi |\n\\      /\n ------ \n"
+		if got := bone.Balloon("hi"); want != got {
+			t.Errorf("want\n%s\ngot\n%s", want, got)
+		}
+	})
+}
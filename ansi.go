@@ -0,0 +1,137 @@
+package bonesay
+
+import "strings"
+
+const (
+	esc = '\x1b'
+	bel = '\a'
+
+	// sgrReset resets the graphic rendition.
+	sgrReset = "\x1b[0m"
+	// linkClose closes the hyperlink (OSC 8).
+	linkClose = "\x1b]8;;\x1b\\"
+)
+
+// escapeLen returns the length of the escape sequence at the start of s.
+// It returns 0 if s does not start with a CSI (e.g. SGR "\x1b[31m") or
+// an OSC (e.g. hyperlink "\x1b]8;;url\x1b\\") sequence which is terminated.
+func escapeLen(s string) int {
+	if len(s) < 2 || s[0] != esc {
+		return 0
+	}
+	switch s[1] {
+	case '[':
+		// parameter bytes, intermediate bytes, then a final byte.
+		for i := 2; i < len(s); i++ {
+			switch c := s[i]; {
+			case 0x20 <= c && c <= 0x3f:
+			case 0x40 <= c && c <= 0x7e:
+				return i + 1
+			default:
+				return 0
+			}
+		}
+	case ']':
+		for i := 2; i < len(s); i++ {
+			switch s[i] {
+			case bel:
+				return i + 1
+			case esc:
+				if i+1 < len(s) && s[i+1] == '\\' {
+					return i + 2
+				}
+				return 0
+			}
+		}
+	}
+	return 0
+}
+
+// visibleWidth returns the number of cells which s occupies on the
+// terminal. Escape sequences occupy no cells.
+func visibleWidth(s string) int {
+	width := 0
+	eachCluster(s, func(cluster string, escape bool) {
+		if !escape {
+			width += clusterWidth(cluster)
+		}
+	})
+	return width
+}
+
+// stripEscapes removes the escape sequences from s.
+func stripEscapes(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if n := escapeLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		b.WriteByte(s[i])
+		i++
+	}
+	return b.String()
+}
+
+// ansiState is the graphic rendition and the hyperlink which are in effect
+// at a point of the text.
+type ansiState struct {
+	sgr  []string
+	link string
+}
+
+// prefix returns the escape sequences which restore the state.
+func (s *ansiState) prefix() string {
+	return strings.Join(s.sgr, "") + s.link
+}
+
+// active reports whether any styling is in effect.
+func (s *ansiState) active() bool {
+	return len(s.sgr) > 0 || s.link != ""
+}
+
+// suffix returns the escape sequences which end the state, so that the
+// border of the balloon is not styled.
+func (s *ansiState) suffix() string {
+	var b strings.Builder
+	if s.link != "" {
+		b.WriteString(linkClose)
+	}
+	if len(s.sgr) > 0 {
+		b.WriteString(sgrReset)
+	}
+	return b.String()
+}
+
+// update updates the state by the escape sequences in text.
+func (s *ansiState) update(text string) {
+	for i := 0; i < len(text); {
+		n := escapeLen(text[i:])
+		if n == 0 {
+			i++
+			continue
+		}
+		seq := text[i : i+n]
+		i += n
+		switch {
+		case seq[1] == '[' && seq[n-1] == 'm':
+			params := seq[2 : n-1]
+			switch {
+			case params == "" || params == "0":
+				s.sgr = nil
+			case strings.HasPrefix(params, "0;"):
+				s.sgr = []string{seq}
+			default:
+				s.sgr = append(s.sgr, seq)
+			}
+		case strings.HasPrefix(seq, "\x1b]8;"):
+			// "\x1b]8;params;uri" + terminator. An empty uri closes the link.
+			body := strings.TrimSuffix(strings.TrimSuffix(seq[4:], "\x1b\\"), "\a")
+			if j := strings.IndexByte(body, ';'); j >= 0 && body[j+1:] != "" {
+				s.link = seq
+			} else {
+				s.link = ""
+			}
+		}
+	}
+}
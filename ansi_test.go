@@ -0,0 +1,106 @@
+package bonesay
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEscapeLen(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"\x1b[31mred", 5},
+		{"\x1b[mreset", 3},
+		{"\x1b[38;5;82m", 10},
+		{"\x1b[2K", 4},
+		{"\x1b]8;;https://example.com\x1b\\link", 26},
+		{"\x1b]0;title\a", 10},
+		{"\x1b[31", 0},
+		{"\x1b]8;;unterminated", 0},
+		{"\x1bc", 0},
+		{"plain", 0},
+	}
+	for _, tt := range tests {
+		if got := escapeLen(tt.s); got != tt.want {
+			t.Errorf("escapeLen(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestVisibleWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"hello", 5},
+		{"\x1b[1;31mhello\x1b[0m", 5},
+		{"\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", 4},
+		{"\x1b[32mこんにちは\x1b[m", 10},
+	}
+	for _, tt := range tests {
+		if got := visibleWidth(tt.s); got != tt.want {
+			t.Errorf("visibleWidth(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestBalloon_ansi(t *testing.T) {
+	bone, err := New(FromString(" $thoughts"), BallonWidth(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("border", func(t *testing.T) {
+		got := bone.Balloon("\x1b[31mred\x1b[0m & \x1b[1mbold\x1b[0m")
+		want := "" +
+			" ____________ \n" +
+			"< \x1b[31mred\x1b[0m & \x1b[1mbold\x1b[0m >\n" +
+			" ------------ \n"
+		if want != got {
+			t.Errorf("want\n%q\ngot\n%q", want, got)
+		}
+	})
+
+	t.Run("styling across lines", func(t *testing.T) {
+		got := bone.Balloon("\x1b[32mgreen text that wraps\x1b[0m done")
+		want := "" +
+			" ____________ \n" +
+			"/ \x1b[32mgreen text\x1b[0m \\\n" +
+			"| \x1b[32mthat wraps\x1b[0m |\n" +
+			"\\ done       /\n" +
+			" ------------ \n"
+		if want != got {
+			t.Errorf("want\n%q\ngot\n%q", want, got)
+		}
+	})
+
+	t.Run("wrap does not break sequences", func(t *testing.T) {
+		got := bone.Balloon("\x1b[38;5;82maaaaaaaaaaaaaaa\x1b[0m")
+		for _, l := range strings.Split(got, "\n") {
+			if strings.Contains(stripEscapes(l), "\x1b") {
+				t.Errorf("broken escape sequence in %q", l)
+			}
+		}
+		want := "" +
+			" ____________ \n" +
+			"/ \x1b[38;5;82maaaaaaaaaa\x1b[0m \\\n" +
+			"\\ \x1b[38;5;82maaaaa\x1b[0m      /\n" +
+			" ------------ \n"
+		if want != got {
+			t.Errorf("want\n%q\ngot\n%q", want, got)
+		}
+	})
+
+	t.Run("hyperlink", func(t *testing.T) {
+		got := bone.Balloon("\x1b]8;;https://example.com\x1b\\example link\x1b]8;;\x1b\\")
+		want := "" +
+			" _________ \n" +
+			"/ \x1b]8;;https://example.com\x1b\\example\x1b]8;;\x1b\\ \\\n" +
+			"\\ \x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\    /\n" +
+			" --------- \n"
+		if want != got {
+			t.Errorf("want\n%q\ngot\n%q", want, got)
+		}
+	})
+}
@@ -1,152 +1,249 @@
 package bonesay
 
 import (
+	"context"
 	"fmt"
 	"strings"
-
-	wordwrap "github.com/Code-Hex/go-wordwrap"
-	runewidth "github.com/mattn/go-runewidth"
 )
 
-type border struct {
-	first  [2]rune
-	middle [2]rune
-	last   [2]rune
-	only   [2]rune
-}
-
-func (bone *Bone) borderType() border {
-	if bone.thinking {
-		return border{
-			first:  [2]rune{'(', ')'},
-			middle: [2]rune{'(', ')'},
-			last:   [2]rune{'(', ')'},
-			only:   [2]rune{'(', ')'},
-		}
-	}
-
-	return border{
-		first:  [2]rune{'/', '\\'},
-		middle: [2]rune{'|', '|'},
-		last:   [2]rune{'\\', '/'},
-		only:   [2]rune{'<', '>'},
-	}
-}
-
 type line struct {
 	text      string
 	runeWidth int
+	// reset ends the styling of the escape sequences in text.
+	reset string
+	// paragraphEnd reports whether the line is the last line of the
+	// paragraph.
+	paragraphEnd bool
+	// rule reports whether the line is a horizontal rule of Markdown.
+	rule bool
 }
 
 type lines []*line
 
+// maxLineWidth returns the width of the widest line, so that the balloon
+// always encloses the text.
 func (bone *Bone) maxLineWidth(lines []*line) int {
 	maxWidth := 0
 	for _, line := range lines {
 		if line.runeWidth > maxWidth {
 			maxWidth = line.runeWidth
 		}
-		if !bone.disableWordWrap && maxWidth > bone.ballonWidth {
-			return bone.ballonWidth
-		}
 	}
 	return maxWidth
 }
 
-func (bone *Bone) getLines(phrase string) []*line {
-	text := bone.canonicalizePhrase(phrase)
-	lineTexts := strings.Split(text, "\n")
-	lines := make([]*line, 0, len(lineTexts))
-	for _, lineText := range lineTexts {
-		lines = append(lines, &line{
-			text:      lineText,
-			runeWidth: runewidth.StringWidth(lineText),
-		})
+// getLines splits the phrase into the lines in the balloon.
+//
+// The styling by escape sequences is ended at the end of each line and
+// restored at the start of the next line, so that the borders are not
+// styled.
+func (bone *Bone) getLines(phrase string, width int) []*line {
+	phrase = sanitizePhrase(phrase, bone.sanitize)
+	var (
+		lines []*line
+		state ansiState
+	)
+	// add appends the lines of a paragraph.
+	add := func(lineTexts []string) {
+		for i, lineText := range lineTexts {
+			// The styling is restored after the indentation.
+			body := strings.TrimLeft(lineText, " ")
+			indent := lineText[:len(lineText)-len(body)]
+			prefix := state.prefix()
+			state.update(lineText)
+			lines = append(lines, &line{
+				text:         indent + prefix + body,
+				runeWidth:    visibleWidth(lineText),
+				reset:        state.suffix(),
+				paragraphEnd: i == len(lineTexts)-1,
+			})
+		}
+	}
+
+	if bone.table {
+		// Each line of the table is a paragraph, so that it is not
+		// justified.
+		for _, text := range bone.tableLines(phrase, width) {
+			add([]string{text})
+		}
+		return lines
+	}
+	for _, paragraph := range strings.Split(phrase, "\n") {
+		switch {
+		case bone.markdown && isRule(paragraph):
+			lines = append(lines, &line{rule: true, paragraphEnd: true})
+		case bone.markdown:
+			add(bone.markdownLines(paragraph, width))
+		default:
+			add(strings.Split(bone.canonicalizePhrase(paragraph, width), "\n"))
+		}
 	}
 	return lines
 }
 
-func (bone *Bone) canonicalizePhrase(phrase string) string {
-	// Replace tab to 8 spaces
-	phrase = strings.Replace(phrase, "\t", "       ", -1)
+func (bone *Bone) canonicalizePhrase(phrase string, width int) string {
+	phrase = expandTabs(phrase, bone.tabWidth)
 
 	if bone.disableWordWrap {
 		return phrase
 	}
-	width := bone.ballonWidth
-	return wordwrap.WrapString(phrase, uint(width))
+	return wrapString(phrase, width, bone.wrapStrategy)
 }
 
-// Balloon to get the balloon and the string entered in the balloon.
-func (bone *Bone) Balloon(phrase string) string {
-	defer bone.buf.Reset()
-
-	lines := bone.getLines(phrase)
-	maxWidth := bone.maxLineWidth(lines)
-
-	bone.writeBallon(lines, maxWidth)
-
-	return bone.buf.String()
+// wrapWidth returns the width at which the phrase is wrapped in the balloon
+// which is placed at offset. The balloon beside the bone is placed at the
+// offset after the bone, so that the width of the bone is taken into
+// account.
+func (bone *Bone) wrapWidth(offset int) int {
+	if bone.fitWidth <= 0 {
+		return bone.ballonWidth
+	}
+	// The balloon is indented by offset-1 columns and its text is enclosed
+	// by the sides and the padding.
+	indent := offset - 1
+	if indent < 0 {
+		indent = 0
+	}
+	width := bone.fitWidth - indent - 2 - 2*bone.paddingX
+	if width < 1 {
+		// There is no room for the balloon, but the text must be wrapped
+		// as narrow as possible.
+		return 1
+	}
+	return width
 }
 
-func (bone *Bone) writeBallon(lines []*line, maxWidth int) {
-	top := make([]byte, 0)
-	bottom := make([]byte, 0)
+// placeBalloon returns the width at which the phrase is wrapped in the
+// balloon which is placed at offset, and the offset at which the balloon
+// is actually placed. If offset leaves less than BallonWidth columns for
+// the text, the balloon is moved to the left so that it is readable.
+func (bone *Bone) placeBalloon(offset int) (int, int) {
+	width := bone.wrapWidth(offset)
+	if bone.fitWidth <= 0 || width >= bone.ballonWidth || offset <= 1 {
+		return width, offset
+	}
+	room := bone.wrapWidth(1)
+	if room <= width {
+		return width, offset
+	}
+	if room > bone.ballonWidth {
+		return bone.ballonWidth, 1 + room - bone.ballonWidth
+	}
+	return room, 1
+}
 
-	for i := 0; i < bone.balloonOffset; i++ {
-		top = append(top, ' ')
-		bottom = append(bottom, ' ')
+// Balloon to get the balloon and the string entered in the balloon.
+//
+// The balloon is placed at $ballonOffset of the bonefile. If the bonefile
+// cannot be loaded, the default offset is used.
+func (bone *Bone) Balloon(phrase string) string {
+	a, err := bone.loadArt()
+	if err != nil {
+		a = &art{balloonOffset: defaultBalloonOffset}
 	}
+	var buf strings.Builder
+	w := newLineWriter(context.Background(), &buf)
+	width, offset := bone.placeBalloon(a.balloonOffset)
+	bone.renderBalloon(w, phrase, width, offset)
+	w.Flush()
+	return buf.String()
+}
 
-	for i := 0; i < maxWidth+2; i++ {
-		top = append(top, '_')
-		bottom = append(bottom, '-')
+// renderBalloon writes the balloon which is placed at offset. The phrase is
+// wrapped at width.
+func (bone *Bone) renderBalloon(w *lineWriter, phrase string, width, offset int) {
+	lines := bone.getLines(phrase, width)
+	maxWidth := bone.maxLineWidth(lines)
+	if maxWidth < bone.minBalloonWidth {
+		maxWidth = bone.minBalloonWidth
 	}
 
-	borderType := bone.borderType()
+	bone.writeBallon(w, lines, maxWidth, offset)
+}
 
-	bone.buf.Write(top)
-	bone.buf.Write([]byte{' ', '\n'})
-	defer func() {
-		bone.buf.Write(bottom)
-		bone.buf.Write([]byte{' ', '\n'})
-	}()
+func (bone *Bone) writeBallon(w *lineWriter, lines []*line, maxWidth, offset int) {
+	style := bone.balloonStyle
+	borderType := style.border(bone.thinking)
+	innerWidth := maxWidth + 2*bone.paddingX
 
-	l := len(lines)
-	if l == 1 {
-		border := borderType.only
-		for i := 0; i < (bone.balloonOffset - 1); i++ {
-			bone.buf.WriteRune(' ')
+	writeEdge(w, style.TopLeft, style.Top, style.TopRight, innerWidth, offset)
+	defer writeEdge(w, style.BottomLeft, style.Bottom, style.BottomRight, innerWidth, offset)
+
+	if bone.paddingY > 0 {
+		padded := make([]*line, 0, len(lines)+2*bone.paddingY)
+		for i := 0; i < bone.paddingY; i++ {
+			padded = append(padded, &line{})
 		}
-		bone.buf.WriteRune(border[0])
-		bone.buf.WriteRune(' ')
-		bone.buf.WriteString(lines[0].text)
-		bone.buf.WriteRune(' ')
-		bone.buf.WriteRune(border[1])
-		bone.buf.WriteRune('\n')
-		return
+		padded = append(padded, lines...)
+		for i := 0; i < bone.paddingY; i++ {
+			padded = append(padded, &line{})
+		}
+		lines = padded
 	}
 
+	l := len(lines)
 	var border [2]rune
 	for i := 0; i < l; i++ {
-		switch i {
-		case 0:
-			border = borderType.first
-		case l - 1:
-			border = borderType.last
+		switch {
+		case l == 1:
+			border = borderType.Only
+		case i == 0:
+			border = borderType.First
+		case i == l-1:
+			border = borderType.Last
 		default:
-			border = borderType.middle
+			border = borderType.Middle
 		}
-		for i := 0; i < (bone.balloonOffset - 1); i++ {
-			bone.buf.WriteRune(' ')
+		writeSpaces(w, offset-1)
+		writeSide(w, border[0])
+		writeSpaces(w, bone.paddingX)
+		if lines[i].rule {
+			writeRule(w, style.Bottom, maxWidth)
+		} else {
+			bone.padding(w, lines[i], maxWidth)
 		}
-		bone.buf.WriteRune(border[0])
-		bone.buf.WriteRune(' ')
-		bone.padding(lines[i], maxWidth)
-		bone.buf.WriteRune(' ')
-		bone.buf.WriteRune(border[1])
-		bone.buf.WriteRune('\n')
+		writeSpaces(w, bone.paddingX)
+		writeSide(w, border[1])
+		w.WriteRune('\n')
+	}
+}
+
+// writeEdge writes the top or the bottom line of the balloon.
+// If fill is zero, nothing is written.
+//
+// A blank corner is omitted at offset 0, so that the classic balloon is
+// not indented, but the corners of a box are always drawn.
+func writeEdge(w *lineWriter, left, fill, right rune, width, offset int) {
+	if fill == 0 {
+		return
+	}
+	writeSpaces(w, offset-1)
+	if offset > 0 || (left != 0 && left != ' ') {
+		writeSide(w, left)
 	}
+	for i := 0; i < width; i++ {
+		w.WriteRune(fill)
+	}
+	writeSide(w, right)
+	w.WriteRune('\n')
+}
+
+// writeRule writes the horizontal rule by fill. If fill is zero, "-" is
+// used.
+func writeRule(w *lineWriter, fill rune, width int) {
+	if fill == 0 {
+		fill = '-'
+	}
+	for i := 0; i < width; i++ {
+		w.WriteRune(fill)
+	}
+}
+
+func writeSide(w *lineWriter, r rune) {
+	if r == 0 {
+		r = ' '
+	}
+	w.WriteRune(r)
 }
 
 func (bone *Bone) flush(text, top, bottom fmt.Stringer) string {
@@ -157,16 +254,3 @@ func (bone *Bone) flush(text, top, bottom fmt.Stringer) string {
 		bottom.String(),
 	)
 }
-
-func (bone *Bone) padding(line *line, maxWidth int) {
-	if maxWidth <= line.runeWidth {
-		bone.buf.WriteString(line.text)
-		return
-	}
-
-	bone.buf.WriteString(line.text)
-	l := maxWidth - line.runeWidth
-	for i := 0; i < l; i++ {
-		bone.buf.WriteRune(' ')
-	}
-}
@@ -0,0 +1,146 @@
+package bonesay
+
+import "sort"
+
+// Border is the pair of the left and right characters of the lines in
+// the balloon. A zero rune is drawn as a space.
+type Border struct {
+	// First is for the first line of multiple lines.
+	First [2]rune
+	// Middle is for the lines between the first and the last line.
+	Middle [2]rune
+	// Last is for the last line of multiple lines.
+	Last [2]rune
+	// Only is for the balloon which has only one line.
+	Only [2]rune
+}
+
+// BalloonStyle is the set of characters to draw the balloon.
+//
+// The top line is drawn with TopLeft, Top repeated and TopRight. If Top is
+// zero, the top line is not drawn. So is the bottom line.
+type BalloonStyle struct {
+	TopLeft, Top, TopRight          rune
+	BottomLeft, Bottom, BottomRight rune
+
+	// Say is the border of the saying bone, and Think is of the thinking
+	// bone.
+	Say, Think Border
+}
+
+func (s BalloonStyle) border(thinking bool) Border {
+	if thinking {
+		return s.Think
+	}
+	return s.Say
+}
+
+// sameBorder returns the Border which uses left and right for all lines.
+func sameBorder(left, right rune) Border {
+	pair := [2]rune{left, right}
+	return Border{First: pair, Middle: pair, Last: pair, Only: pair}
+}
+
+// boxStyle returns the BalloonStyle of box drawing characters.
+// corners are top left, top right, bottom left and bottom right.
+func boxStyle(horizontal, vertical rune, corners [4]rune) BalloonStyle {
+	return BalloonStyle{
+		TopLeft:     corners[0],
+		Top:         horizontal,
+		TopRight:    corners[1],
+		BottomLeft:  corners[2],
+		Bottom:      horizontal,
+		BottomRight: corners[3],
+		Say:         sameBorder(vertical, vertical),
+		Think:       sameBorder(vertical, vertical),
+	}
+}
+
+// Built-in balloon styles.
+var (
+	// ClassicBalloon is the balloon of the original cowsay. It is the default.
+	ClassicBalloon = BalloonStyle{
+		TopLeft:     ' ',
+		Top:         '_',
+		TopRight:    ' ',
+		BottomLeft:  ' ',
+		Bottom:      '-',
+		BottomRight: ' ',
+		Say: Border{
+			First:  [2]rune{'/', '\\'},
+			Middle: [2]rune{'|', '|'},
+			Last:   [2]rune{'\\', '/'},
+			Only:   [2]rune{'<', '>'},
+		},
+		Think: sameBorder('(', ')'),
+	}
+
+	// SingleBalloon is the box of single lines.
+	SingleBalloon = boxStyle('─', '│', [4]rune{'┌', '┐', '└', '┘'})
+
+	// DoubleBalloon is the box of double lines.
+	DoubleBalloon = boxStyle('═', '║', [4]rune{'╔', '╗', '╚', '╝'})
+
+	// RoundedBalloon is the box of single lines with rounded corners.
+	RoundedBalloon = boxStyle('─', '│', [4]rune{'╭', '╮', '╰', '╯'})
+
+	// HeavyBalloon is the box of heavy lines.
+	HeavyBalloon = boxStyle('━', '┃', [4]rune{'┏', '┓', '┗', '┛'})
+
+	// ASCIIBalloon is the box which is drawn only with ASCII characters.
+	ASCIIBalloon = BalloonStyle{
+		TopLeft:     '+',
+		Top:         '-',
+		TopRight:    '+',
+		BottomLeft:  '+',
+		Bottom:      '-',
+		BottomRight: '+',
+		Say:         sameBorder('|', '|'),
+		Think:       sameBorder('(', ')'),
+	}
+
+	// NoBalloon draws only the text without any borders.
+	NoBalloon = BalloonStyle{
+		Say:   sameBorder(' ', ' '),
+		Think: sameBorder(' ', ' '),
+	}
+)
+
+var balloonStyles = map[string]*BalloonStyle{
+	"classic": &ClassicBalloon,
+	"single":  &SingleBalloon,
+	"double":  &DoubleBalloon,
+	"rounded": &RoundedBalloon,
+	"heavy":   &HeavyBalloon,
+	"ascii":   &ASCIIBalloon,
+	"none":    &NoBalloon,
+}
+
+// LookupBalloonStyle returns the built-in balloon style by name.
+// See BalloonStyles for the names.
+func LookupBalloonStyle(name string) (BalloonStyle, bool) {
+	style, ok := balloonStyles[name]
+	if !ok {
+		return BalloonStyle{}, false
+	}
+	return *style, true
+}
+
+// BalloonStyles returns the sorted names of the built-in balloon styles.
+func BalloonStyles() []string {
+	names := make([]string, 0, len(balloonStyles))
+	for name := range balloonStyles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithBalloonStyle specifies the style of the balloon.
+// The default is ClassicBalloon.
+func WithBalloonStyle(style BalloonStyle) Option {
+	return func(c *Bone) error {
+		c.balloonStyle = style
+		return nil
+	}
+}
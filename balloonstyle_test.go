@@ -0,0 +1,140 @@
+package bonesay
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestWithBalloonStyle(t *testing.T) {
+	tests := []struct {
+		name     string
+		style    BalloonStyle
+		thinking bool
+		phrase   string
+		want     string
+	}{
+		{
+			name:   "classic",
+			style:  ClassicBalloon,
+			phrase: "hello\nworld",
+			want:   " _______ \n/ hello \\\n\\ world /\n ------- \n",
+		},
+		{
+			name:     "classic thinking",
+			style:    ClassicBalloon,
+			thinking: true,
+			phrase:   "hi",
+			want:     " ____ \n( hi )\n ---- \n",
+		},
+		{
+			name:   "single",
+			style:  SingleBalloon,
+			phrase: "hello\nbone\nworld",
+			want:   "┌───────┐\n│ hello │\n│ bone  │\n│ world │\n└───────┘\n",
+		},
+		{
+			name:   "rounded",
+			style:  RoundedBalloon,
+			phrase: "hi",
+			want:   "╭────╮\n│ hi │\n╰────╯\n",
+		},
+		{
+			name:   "double",
+			style:  DoubleBalloon,
+			phrase: "hi",
+			want:   "╔════╗\n║ hi ║\n╚════╝\n",
+		},
+		{
+			name:   "heavy",
+			style:  HeavyBalloon,
+			phrase: "hi",
+			want:   "┏━━━━┓\n┃ hi ┃\n┗━━━━┛\n",
+		},
+		{
+			name:     "ascii thinking",
+			style:    ASCIIBalloon,
+			thinking: true,
+			phrase:   "hello\nworld",
+			want:     "+-------+\n( hello )\n( world )\n+-------+\n",
+		},
+		{
+			name:   "none",
+			style:  NoBalloon,
+			phrase: "hello\nworld",
+			want:   "  hello  \n  world  \n",
+		},
+		{
+			name: "user-defined",
+			style: BalloonStyle{
+				Top:    '~',
+				Bottom: '~',
+				Say:    Border{Only: [2]rune{'{', '}'}},
+			},
+			phrase: "hi",
+			want:   " ~~~~ \n{ hi }\n ~~~~ \n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []Option{FromString(" $thoughts"), WithBalloonStyle(tt.style)}
+			if tt.thinking {
+				opts = append(opts, Thinking())
+			}
+			bone, err := New(opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := bone.Balloon(tt.phrase); tt.want != got {
+				t.Errorf("want\n%s\ngot\n%s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWithBalloonStyle_ZeroOffset(t *testing.T) {
+	source := NewSource("zero", fstest.MapFS{
+		"zero.bone": {Data: []byte("$ballonOffset = 0;\n$the_bone = <<EOB;\n$thoughts\nEOB\n")},
+	})
+	tests := []struct {
+		name  string
+		style BalloonStyle
+		want  string
+	}{
+		{
+			name:  "classic",
+			style: ClassicBalloon,
+			want:  "____ \n< hi >\n---- \n",
+		},
+		{
+			name:  "single",
+			style: SingleBalloon,
+			want:  "┌────┐\n│ hi │\n└────┘\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bone, err := New(WithSources(source), Type("zero"), WithBalloonStyle(tt.style))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := bone.Balloon("hi"); tt.want != got {
+				t.Errorf("want\n%s\ngot\n%s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLookupBalloonStyle(t *testing.T) {
+	for _, name := range BalloonStyles() {
+		if _, ok := LookupBalloonStyle(name); !ok {
+			t.Errorf("%q is not found", name)
+		}
+	}
+	style, ok := LookupBalloonStyle("rounded")
+	if !ok || style != RoundedBalloon {
+		t.Errorf("want %v, but got %v", RoundedBalloon, style)
+	}
+	if _, ok := LookupBalloonStyle("unknown"); ok {
+		t.Error("want not found")
+	}
+}
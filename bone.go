@@ -1,23 +1,49 @@
 package bonesay
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"strings"
+	"unicode/utf8"
 )
 
 // Bone struct!!
+//
+// Bone is immutable after New or Clone, so it is safe to share a Bone
+// between goroutines.
 type Bone struct {
 	eyes            string
 	tongue          string
 	typ             *BoneFile
 	thoughts        rune
+	thoughtsSet     bool
 	thinking        bool
 	ballonWidth     int
+	fitWidth        int
 	disableWordWrap bool
-	balloonOffset   int
+	sources         []BoneSource
+	watcher         *Watcher
+	balloonStyle    BalloonStyle
+	balloonPosition BalloonPosition
+	align           Alignment
+	paddingX        int
+	paddingY        int
+	minBalloonWidth int
+	sanitize        SanitizePolicy
+	tabWidth        int
+	wrapStrategy    WrapStrategy
+	markdown        bool
+	table           bool
+	tableFormat     TableFormat
+	vars            map[string]string
 
-	buf strings.Builder
+	// rand is used by Random only while the options are applied.
+	rand *rand.Rand
+	// resolve is the last Type or Random which resolves the bonefile after
+	// all the options are applied, so that it sees WithSources and
+	// WithWatcher which are specified after it.
+	resolve func(*Bone) error
 }
 
 // New returns pointer of Bone struct that made by options
@@ -30,24 +56,28 @@ func New(options ...Option) (*Bone, error) {
 			Name:         "mobile",
 			BasePath:     "bones",
 			LocationType: InBinary,
+			Source:       BinarySource(),
 		},
-		ballonWidth: 15,
+		ballonWidth:  15,
+		balloonStyle: ClassicBalloon,
+		paddingX:     1,
+		tabWidth:     defaultTabWidth,
 	}
-	for _, o := range options {
-		if err := o(bone); err != nil {
-			return nil, err
-		}
+	if err := bone.apply(options); err != nil {
+		return nil, err
 	}
 	return bone, nil
 }
 
 // Say returns string that said by bone
+//
+// See also Render.
 func (bone *Bone) Say(phrase string) (string, error) {
-	mow, err := bone.GetBone()
-	if err != nil {
+	var buf strings.Builder
+	if _, err := bone.Render(context.Background(), &buf, phrase); err != nil {
 		return "", err
 	}
-	return bone.Balloon(phrase) + mow, nil
+	return buf.String(), nil
 }
 
 // Clone returns a copy of bone.
@@ -56,11 +86,8 @@ func (bone *Bone) Say(phrase string) (string, error) {
 func (bone *Bone) Clone(options ...Option) (*Bone, error) {
 	ret := new(Bone)
 	*ret = *bone
-	ret.buf.Reset()
-	for _, o := range options {
-		if err := o(ret); err != nil {
-			return nil, err
-		}
+	if err := ret.apply(options); err != nil {
+		return nil, err
 	}
 	return ret, nil
 }
@@ -69,45 +96,67 @@ func (bone *Bone) Clone(options ...Option) (*Bone, error) {
 type Option func(*Bone) error
 
 // Eyes specifies eyes
-// The specified string will always be adjusted to be equal to two characters.
+// The specified string will always be adjusted to the width of the eyes in
+// the bonefile, which is two cells unless $eyes_width is declared. Shorter
+// eyes are padded with spaces, and longer eyes are truncated. It is an
+// error if a wide character does not fit in the width.
 func Eyes(s string) Option {
 	return func(c *Bone) error {
-		c.eyes = adjustTo2Chars(s)
+		if !utf8.ValidString(s) {
+			return fmt.Errorf("invalid eyes %q, it must be valid UTF-8", s)
+		}
+		c.eyes = s
 		return nil
 	}
 }
 
 // Tongue specifies tongue
-// The specified string will always be adjusted to be less than or equal to two characters.
+// The specified string will always be adjusted to the width of the tongue
+// in the bonefile, which is two cells unless $tongue_width is declared.
+// See also Eyes.
 func Tongue(s string) Option {
 	return func(c *Bone) error {
-		c.tongue = adjustTo2Chars(s)
+		if !utf8.ValidString(s) {
+			return fmt.Errorf("invalid tongue %q, it must be valid UTF-8", s)
+		}
+		c.tongue = s
 		return nil
 	}
 }
 
-func adjustTo2Chars(s string) string {
-	if len(s) >= 2 {
-		return s[:2]
+// apply applies the options to bone, and then resolves the bonefile which
+// is specified by Type or Random.
+func (bone *Bone) apply(options []Option) error {
+	defer func() {
+		bone.rand = nil
+		bone.resolve = nil
+	}()
+	for _, o := range options {
+		if err := o(bone); err != nil {
+			return err
+		}
 	}
-	if len(s) == 1 {
-		return s + " "
+	if bone.resolve == nil {
+		return nil
 	}
-	return "  "
+	return bone.resolve(bone)
 }
 
-func containBones(target string) (*BoneFile, error) {
-	bonePaths, err := Bones()
-	if err != nil {
-		return nil, err
+// bonePaths returns the list of bones which can be used by the bone.
+func (bone *Bone) bonePaths() ([]*BonePath, error) {
+	if bone.watcher != nil {
+		return bone.watcher.Bones(), nil
 	}
-	for _, bonePath := range bonePaths {
-		bonefile, ok := bonePath.Lookup(target)
-		if ok {
-			return bonefile, nil
-		}
+	if bone.sources == nil {
+		return Bones()
 	}
-	return nil, nil
+	return BonesIn(bone.sources...)
+}
+
+// BoneFile returns the bonefile which is used by the bone.
+func (bone *Bone) BoneFile() *BoneFile {
+	bonefile := *bone.typ
+	return &bonefile
 }
 
 // NotFound is indicated not found the bonefile.
@@ -122,20 +171,28 @@ func (n *NotFound) Error() string {
 }
 
 // Type specify name of the bonefile
+//
+// The name is resolved after all the options are applied, so the bonefile
+// is looked up in WithSources or WithWatcher wherever they are specified.
+// If Type or Random is specified more than once, the last one is used.
 func Type(s string) Option {
 	if s == "" {
 		s = "default"
 	}
 	return func(c *Bone) error {
-		bonefile, err := containBones(s)
-		if err != nil {
-			return err
-		}
-		if bonefile != nil {
-			c.typ = bonefile
+		c.resolve = func(c *Bone) error {
+			bonePaths, err := c.bonePaths()
+			if err != nil {
+				return err
+			}
+			r, err := Resolve(bonePaths, s)
+			if err != nil {
+				return err
+			}
+			c.typ = r.BoneFile
 			return nil
 		}
-		return &NotFound{Bonefile: s}
+		return nil
 	}
 }
 
@@ -150,39 +207,75 @@ func Thinking() Option {
 // Thoughts Thoughts allows you to specify
 // the rune that will be drawn between
 // the speech bubbles and the bone
+//
+// Unless it is specified, the original Perl ".cow" files are drawn with
+// '\' in saying mode and 'o' in thinking mode as cowsay does.
 func Thoughts(thoughts rune) Option {
 	return func(c *Bone) error {
 		c.thoughts = thoughts
+		c.thoughtsSet = true
 		return nil
 	}
 }
 
 // Random specifies something .bone from bones directory
-func Random() Option {
-	pick, err := pickBone()
+//
+// Each bonefile which is resolved by Type is picked with the same
+// probability unless Weights is specified. opts filter the bonefiles.
+// If no bonefile is left, it returns ErrNoCandidates.
+//
+// The bone is picked by the source which is specified by Seed. If Seed is
+// not specified, a source which is seeded at the start of the program is
+// used. See also RandomWithSource.
+//
+// The bone is picked after all the options are applied in the same way as
+// Type.
+func Random(opts ...RandomOption) Option {
 	return func(c *Bone) error {
-		if err != nil {
-			return err
+		r := c.rand
+		if r == nil {
+			r = globalRand
+		}
+		c.resolve = func(c *Bone) error {
+			return c.pickBoneWith(r, opts)
 		}
-		c.typ = pick
 		return nil
 	}
 }
 
-func pickBone() (*BoneFile, error) {
-	bonePaths, err := Bones()
-	if err != nil {
-		return nil, err
+// RandomWithSource specifies something .bone from bones directory which is
+// picked by src. The same src which is seeded with the same value picks the
+// same bone as long as the bonefiles are unchanged.
+func RandomWithSource(src rand.Source, opts ...RandomOption) Option {
+	return func(c *Bone) error {
+		r := rand.New(src)
+		c.resolve = func(c *Bone) error {
+			return c.pickBoneWith(r, opts)
+		}
+		return nil
+	}
+}
+
+// Seed specifies the seed of Random, so that Random is reproducible.
+// It must be specified before Random.
+func Seed(seed int64) Option {
+	return func(c *Bone) error {
+		c.rand = rand.New(rand.NewSource(seed))
+		return nil
 	}
-	bonePath := bonePaths[rand.Intn(len(bonePaths))]
+}
 
-	n := len(bonePath.BoneFiles)
-	bonefile := bonePath.BoneFiles[rand.Intn(n)]
-	return &BoneFile{
-		Name:         bonefile,
-		BasePath:     bonePath.Name,
-		LocationType: bonePath.LocationType,
-	}, nil
+func (bone *Bone) pickBoneWith(r *rand.Rand, opts []RandomOption) error {
+	bonePaths, err := bone.bonePaths()
+	if err != nil {
+		return err
+	}
+	pick, err := pickBone(r, bonePaths, opts)
+	if err != nil {
+		return err
+	}
+	bone.typ = pick
+	return nil
 }
 
 // BallonWidth specifies ballon size
@@ -193,6 +286,19 @@ func BallonWidth(size uint) Option {
 	}
 }
 
+// FitWidth specifies the total width of the output, e.g. the width of the
+// terminal. The balloon width is computed from total so that the balloon
+// which is placed at $ballonOffset of the bonefile, or beside the bone,
+// fits in total, and it overrides BallonWidth. If $ballonOffset leaves
+// less than BallonWidth columns for the text, the balloon is moved to the
+// left instead. If total is not positive, BallonWidth is used.
+func FitWidth(total int) Option {
+	return func(c *Bone) error {
+		c.fitWidth = total
+		return nil
+	}
+}
+
 // DisableWordWrap disables word wrap.
 // Ignoring width of the ballon.
 func DisableWordWrap() Option {
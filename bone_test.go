@@ -3,10 +3,13 @@ package bonesay
 import (
 	"errors"
 	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
 
 	"github.com/google/go-cmp/cmp"
-	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 func TestBone_Clone(t *testing.T) {
@@ -33,14 +36,14 @@ func TestBone_Clone(t *testing.T) {
 			opts: []Option{},
 			from: func() *Bone {
 				bone, _ := New(
-					Type("docker"),
+					Type("hat"),
 					BallonWidth(60),
 				)
 				return bone
 			}(),
 			want: func() *Bone {
 				bone, _ := New(
-					Type("docker"),
+					Type("hat"),
 					BallonWidth(60),
 				)
 				return bone
@@ -54,14 +57,14 @@ func TestBone_Clone(t *testing.T) {
 			},
 			from: func() *Bone {
 				bone, _ := New(
-					Type("docker"),
+					Type("hat"),
 					BallonWidth(60),
 				)
 				return bone
 			}(),
 			want: func() *Bone {
 				bone, _ := New(
-					Type("docker"),
+					Type("hat"),
 					BallonWidth(60),
 					Thinking(),
 					Thoughts('o'),
@@ -77,8 +80,7 @@ func TestBone_Clone(t *testing.T) {
 				t.Fatal(err)
 			}
 			if diff := cmp.Diff(tt.want, got,
-				cmp.AllowUnexported(Bone{}),
-				cmpopts.IgnoreFields(Bone{}, "buf")); diff != "" {
+				cmp.AllowUnexported(Bone{})); diff != "" {
 				t.Errorf("(-want, +got)\n%s", diff)
 			}
 		})
@@ -97,8 +99,7 @@ func TestBone_Clone(t *testing.T) {
 		cloned, _ := bone.Clone()
 
 		if diff := cmp.Diff(bone, cloned,
-			cmp.AllowUnexported(Bone{}),
-			cmpopts.IgnoreFields(Bone{}, "buf")); diff != "" {
+			cmp.AllowUnexported(Bone{})); diff != "" {
 			t.Errorf("(-want, +got)\n%s", diff)
 		}
 	})
@@ -119,49 +120,289 @@ func TestBone_Clone(t *testing.T) {
 	})
 }
 
-func Test_adjustTo2Chars(t *testing.T) {
+func TestNotFound_Error(t *testing.T) {
+	file := "test"
+	n := &NotFound{
+		Bonefile: file,
+	}
+	want := fmt.Sprintf("not found %q bonefile", file)
+	if want != n.Error() {
+		t.Fatalf("want %q but got %q", want, n.Error())
+	}
+}
+
+func TestBone_concurrent(t *testing.T) {
+	bone, err := New(Type("hat"), BallonWidth(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSay, err := bone.Say("concurrent bones are fun")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantBalloon := bone.Balloon("concurrent bones are fun")
+	wantBone, err := bone.GetBone()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n, m = 32, 10
+	var wg sync.WaitGroup
+	// Each iteration sends at most 4 errors, so that no goroutine blocks.
+	errs := make(chan error, n*m*4)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < m; j++ {
+				if got, err := bone.Say("concurrent bones are fun"); err != nil || got != wantSay {
+					errs <- fmt.Errorf("unexpected Say: %v\n%s", err, got)
+				}
+				if got := bone.Balloon("concurrent bones are fun"); got != wantBalloon {
+					errs <- fmt.Errorf("unexpected Balloon:\n%s", got)
+				}
+				if got, err := bone.GetBone(); err != nil || got != wantBone {
+					errs <- fmt.Errorf("unexpected GetBone: %v\n%s", err, got)
+				}
+				cloned, err := bone.Clone(Thinking(), Thoughts('o'))
+				if err != nil {
+					errs <- err
+					continue
+				}
+				if _, err := cloned.Say("cloned"); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestSeed(t *testing.T) {
+	pick := func(opts ...Option) []string {
+		t.Helper()
+		names := make([]string, 0, 10)
+		for i := 0; i < 10; i++ {
+			bone, err := New(opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, bone.typ.Name)
+		}
+		return names
+	}
+
+	t.Run("Seed", func(t *testing.T) {
+		want := pick(Seed(42), Random())
+		if diff := cmp.Diff(want, pick(Seed(42), Random())); diff != "" {
+			t.Errorf("(-want, +got)\n%s", diff)
+		}
+		// Each New starts the sequence from the seed.
+		for _, name := range want[1:] {
+			if name != want[0] {
+				t.Errorf("want %q, but got %q", want[0], name)
+			}
+		}
+	})
+
+	t.Run("RandomWithSource", func(t *testing.T) {
+		src1, src2 := rand.NewSource(7), rand.NewSource(7)
+		if diff := cmp.Diff(pick(RandomWithSource(src1)), pick(RandomWithSource(src2))); diff != "" {
+			t.Errorf("(-want, +got)\n%s", diff)
+		}
+	})
+
+	t.Run("not kept", func(t *testing.T) {
+		bone, err := New(Seed(42), Random())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bone.rand != nil {
+			t.Error("the seeded source must not be kept in the bone")
+		}
+	})
+}
+
+func TestFitWidth(t *testing.T) {
+	source := NewSource("fit", fstest.MapFS{
+		"wide.bone": {Data: []byte("$ballonOffset = 11;\n$the_bone = <<EOB;\n          $thoughts\n   ($eyes)\nEOB\n")},
+	})
+	phrase := strings.Repeat("a", 40)
 	tests := []struct {
 		name string
-		s    string
-		want string
+		opts []Option
+		want int
 	}{
 		{
-			name: "empty",
-			s:    "",
-			want: "  ",
-		},
-		{
-			name: "1 character",
-			s:    "1",
-			want: "1 ",
+			name: "fit",
+			opts: []Option{FitWidth(30)},
+			want: 30,
 		},
 		{
-			name: "2 characters",
-			s:    "12",
-			want: "12",
+			name: "padding",
+			opts: []Option{FitWidth(30), Padding(3, 0)},
+			want: 30,
 		},
 		{
-			name: "3 characters",
-			s:    "123",
-			want: "12",
+			name: "no room",
+			opts: []Option{FitWidth(12), BallonWidth(5)},
+			// The balloon of 5 cells is moved to the left.
+			want: 12,
 		},
 	}
 	for _, tt := range tests {
-		t.Run(tt.s, func(t *testing.T) {
-			if got := adjustTo2Chars(tt.s); got != tt.want {
-				t.Errorf("adjustTo2Chars() = %v, want %v", got, tt.want)
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithSources(source), Type("wide")}, tt.opts...)
+			got, err := Say(phrase, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			width := 0
+			for _, line := range strings.Split(got, "\n") {
+				if w := visibleWidth(line); w > width {
+					width = w
+				}
+			}
+			if width != tt.want {
+				t.Errorf("want %d columns, but got %d:\n%s", tt.want, width, got)
 			}
 		})
 	}
 }
 
-func TestNotFound_Error(t *testing.T) {
-	file := "test"
-	n := &NotFound{
-		Bonefile: file,
+func TestFitWidth_DefaultBone(t *testing.T) {
+	isolateXDG(t)
+	art, err := New()
+	if err != nil {
+		t.Fatal(err)
 	}
-	want := fmt.Sprintf("not found %q bonefile", file)
-	if want != n.Error() {
-		t.Fatalf("want %q but got %q", want, n.Error())
+	artText, err := art.GetBone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	artWidth := maxVisibleWidth(strings.Split(artText, "\n"))
+
+	got, err := Say("the balloon must be readable", FitWidth(80))
+	if err != nil {
+		t.Fatal(err)
 	}
+	lines := strings.Split(got, "\n")
+	want := 80
+	if artWidth > want {
+		want = artWidth
+	}
+	if width := maxVisibleWidth(lines); width > want {
+		t.Errorf("want at most %d columns, but got %d:\n%s", want, width, got)
+	}
+	// The phrase is not wrapped at every cell.
+	if !strings.Contains(got, "the balloon must") {
+		t.Errorf("want the readable balloon, but got\n%s", got)
+	}
+}
+
+func TestFitWidth_Positions(t *testing.T) {
+	source := NewSource("fit", fstest.MapFS{
+		"bone.bone": {Data: []byte("$ballonOffset = 4;\n$the_bone = <<EOB;\n   $thoughts\n  ($eyes)____\n  (__)    )\nEOB\n")},
+	})
+	phrase := "the balloon must fit in the width with the bone"
+	outputWidth := func(t *testing.T, opts ...Option) int {
+		t.Helper()
+		got, err := Say(phrase, append([]Option{WithSources(source), Type("bone")}, opts...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return maxVisibleWidth(strings.Split(got, "\n"))
+	}
+	for _, position := range []BalloonPosition{BalloonAbove, BalloonBelow, BalloonLeft, BalloonRight} {
+		t.Run(position.String(), func(t *testing.T) {
+			// The output with the narrowest balloon is wider than total if
+			// the bone does not leave room for the balloon.
+			narrowest := outputWidth(t, WithBalloonPosition(position), BallonWidth(1))
+			for total := 1; total <= 60; total++ {
+				want := total
+				if want < narrowest {
+					want = narrowest
+				}
+				if got := outputWidth(t, WithBalloonPosition(position), FitWidth(total)); got > want {
+					t.Errorf("FitWidth(%d): want at most %d columns, but got %d", total, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestEyes(t *testing.T) {
+	source := NewSource("eyes", fstest.MapFS{
+		"face.bone": {Data: []byte("$the_bone = <<EOB;\n($eyes)\n ($tongue)|\nEOB\n")},
+		"wide.bone": {Data: []byte("$eyes_width = 4;\n$tongue_width = 1;\n$the_bone = <<EOB;\n($eyes)\n ($tongue)|\nEOB\n")},
+	})
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "emoji",
+			opts: []Option{Type("face"), Eyes("👀"), Tongue("👅")},
+			want: "(👀)\n (👅)|",
+		},
+		{
+			name: "multi-byte",
+			opts: []Option{Type("face"), Eyes("ôôô"), Tongue("ü")},
+			want: "(ôô)\n (ü )|",
+		},
+		{
+			name: "CJK",
+			opts: []Option{Type("face"), Eyes("目目")},
+			want: "(目)\n (  )|",
+		},
+		{
+			name: "declared width",
+			opts: []Option{Type("wide"), Eyes("目目"), Tongue("U")},
+			want: "(目目)\n (U)|",
+		},
+		{
+			name: "padded to declared width",
+			opts: []Option{Type("wide"), Eyes("👀")},
+			want: "(👀  )\n ( )|",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bone, err := New(append([]Option{WithSources(source)}, tt.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := bone.GetBone()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want != got {
+				t.Errorf("want\n%s\n-----got\n%s", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("does not fit", func(t *testing.T) {
+		bone, err := New(WithSources(source), Type("wide"), Tongue("👅"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := bone.GetBone(); err == nil {
+			t.Error("want error, but got nil")
+		}
+	})
+
+	t.Run("invalid UTF-8", func(t *testing.T) {
+		if _, err := New(Eyes("\xf0\x9f")); err == nil {
+			t.Error("want error for eyes, but got nil")
+		}
+		if _, err := New(Tongue("\xc3")); err == nil {
+			t.Error("want error for tongue, but got nil")
+		}
+	})
 }
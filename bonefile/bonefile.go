@@ -0,0 +1,175 @@
+// Package bonefile implements a parser for bonefiles.
+//
+// A bonefile is a small subset of Perl, inherited from the original cowsay:
+//
+//	##
+//	## comments
+//	##
+//	$ballonOffset = 57
+//	$the_bone = <<EOB;
+//	        $thoughts   ^__^
+//	         $thoughts  ($eyes)\\_______
+//	EOB
+//
+// The header consists of comments and scalar assignments (directives).
+// The art itself is a heredoc whose body may refer to variables such as
+// $eyes or ${tongue}.
+package bonefile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pos is a position in a bonefile.
+// Line and Column are 1-based, Column counts bytes.
+type Pos struct {
+	Line   int
+	Column int
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// File is a parsed bonefile.
+type File struct {
+	// Name is the name of the file used in error messages.
+	Name string
+	// Comments are the comment lines in the header.
+	Comments []*Comment
+	// Directives are the scalar assignments in the header.
+	Directives []*Directive
+	// Body is the heredoc which contains the art.
+	Body *Body
+}
+
+// Directive returns the last directive which is assigned to name.
+// If it is not found, returns nil.
+func (f *File) Directive(name string) *Directive {
+	var found *Directive
+	for _, d := range f.Directives {
+		if d.Name == name {
+			found = d
+		}
+	}
+	return found
+}
+
+// Comment is a comment line such as "## Bone".
+type Comment struct {
+	Pos  Pos
+	Text string
+}
+
+// Directive is an assignment in the header such as "$ballonOffset = 57".
+type Directive struct {
+	Pos Pos
+	// Name is the variable name without "$".
+	Name string
+	// ValuePos is the position of Value.
+	ValuePos Pos
+	// Value is the assigned value with surrounding spaces and ";" trimmed.
+	Value string
+}
+
+// Body is the heredoc which contains the art.
+type Body struct {
+	Pos Pos
+	// Name is the assigned variable name without "$". e.g. "the_bone".
+	Name string
+	// Delimiter is the heredoc terminator. e.g. "EOB".
+	Delimiter string
+	// Interpolate reports whether variables and escapes are expanded.
+	// It is false when the delimiter is single-quoted.
+	Interpolate bool
+	// Segments are the contents of the heredoc.
+	Segments []Segment
+}
+
+// Segment is a part of the heredoc body.
+type Segment interface {
+	Pos() Pos
+	segment()
+}
+
+// Text is a literal text in the heredoc body. Escapes are already decoded.
+type Text struct {
+	ValuePos Pos
+	Value    string
+}
+
+// Variable is a variable reference such as $eyes or ${eyes}.
+type Variable struct {
+	NamePos Pos
+	// Name is the variable name without "$", "{" and "}".
+	Name string
+	// Braced reports whether the reference was written as ${name}.
+	Braced bool
+}
+
+// Pos returns the position of the text.
+func (t *Text) Pos() Pos { return t.ValuePos }
+
+// Pos returns the position of the variable reference.
+func (v *Variable) Pos() Pos { return v.NamePos }
+
+func (*Text) segment()     {}
+func (*Variable) segment() {}
+
+// Variables returns the names of variables which are referred in the body.
+// Each name appears only once, in order of first appearance.
+func (b *Body) Variables() []string {
+	seen := make(map[string]bool)
+	names := make([]string, 0)
+	for _, seg := range b.Segments {
+		if v, ok := seg.(*Variable); ok && !seen[v.Name] {
+			seen[v.Name] = true
+			names = append(names, v.Name)
+		}
+	}
+	return names
+}
+
+// Expand returns the art whose variables are replaced with vars.
+// If the body refers to a variable which is not in vars, it returns *Error.
+func (f *File) Expand(vars map[string]string) (string, error) {
+	var buf strings.Builder
+	for _, seg := range f.Body.Segments {
+		switch seg := seg.(type) {
+		case *Text:
+			buf.WriteString(seg.Value)
+		case *Variable:
+			val, ok := vars[seg.Name]
+			if !ok {
+				return "", f.errorf(seg.NamePos, "undefined variable $%s", seg.Name)
+			}
+			buf.WriteString(val)
+		}
+	}
+	return buf.String(), nil
+}
+
+// Error is an error which is occurred while parsing or expanding a bonefile.
+type Error struct {
+	Filename string
+	Pos      Pos
+	Msg      string
+}
+
+var _ error = (*Error)(nil)
+
+func (e *Error) Error() string {
+	if e.Filename == "" {
+		return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+	}
+	return fmt.Sprintf("%s:%s: %s", e.Filename, e.Pos, e.Msg)
+}
+
+func (f *File) errorf(pos Pos, format string, args ...interface{}) *Error {
+	return &Error{
+		Filename: f.Name,
+		Pos:      pos,
+		Msg:      fmt.Sprintf(format, args...),
+	}
+}
@@ -0,0 +1,98 @@
+package bonefile
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// unescapeBone decodes the escape at the beginning of s, which starts with "\".
+// It returns the decoded string and the number of consumed bytes.
+// If s does not start with an escape, n is 0.
+func unescapeBone(s string) (decoded string, n int, err error) {
+	if len(s) >= 2 && (s[1] == '\\' || s[1] == '@' || s[1] == '$') {
+		return s[1:2], 2, nil
+	}
+	return "", 0, nil
+}
+
+// unescapePerl is like unescapeBone but decodes the escape as Perl does
+// in double-quoted strings.
+func unescapePerl(s string) (decoded string, n int, err error) {
+	if len(s) < 2 {
+		return "", 0, nil
+	}
+	switch c := s[1]; c {
+	case 't':
+		return "\t", 2, nil
+	case 'n':
+		return "\n", 2, nil
+	case 'r':
+		return "\r", 2, nil
+	case 'f':
+		return "\f", 2, nil
+	case 'b':
+		return "\b", 2, nil
+	case 'a':
+		return "\a", 2, nil
+	case 'e':
+		return "\x1b", 2, nil
+	case '0', '1', '2', '3', '4', '5', '6', '7':
+		n = 1
+		for n < 3 && 1+n < len(s) && '0' <= s[1+n] && s[1+n] <= '7' {
+			n++
+		}
+		v, _ := strconv.ParseUint(s[1:1+n], 8, 32)
+		return string(rune(v)), 1 + n, nil
+	case 'x':
+		if strings.HasPrefix(s[2:], "{") {
+			end := strings.IndexByte(s, '}')
+			if end < 0 {
+				return "", 0, errors.New(`missing "}" in \x{...} escape`)
+			}
+			return decodeHex(s[3:end], end+1)
+		}
+		n = 0
+		for n < 2 && 2+n < len(s) && isHex(s[2+n]) {
+			n++
+		}
+		if n == 0 {
+			return "\x00", 2, nil
+		}
+		return decodeHex(s[2:2+n], 2+n)
+	case 'c':
+		if len(s) < 3 {
+			return "", 0, errors.New(`missing control character after \c`)
+		}
+		ctrl := s[2]
+		if 'a' <= ctrl && ctrl <= 'z' {
+			ctrl -= 'a' - 'A'
+		}
+		return string(rune(ctrl ^ 64)), 3, nil
+	case 'N':
+		end := strings.IndexByte(s, '}')
+		if !strings.HasPrefix(s[2:], "{U+") || end < 0 {
+			return "", 0, errors.New(`unsupported escape \N, only \N{U+XXXX} is supported`)
+		}
+		return decodeHex(s[5:end], end+1)
+	case 'l', 'u', 'L', 'U', 'Q', 'E', 'F':
+		return "", 0, fmt.Errorf(`unsupported escape \%c`, c)
+	default:
+		_, size := utf8.DecodeRuneInString(s[1:])
+		return s[1 : 1+size], 1 + size, nil
+	}
+}
+
+func decodeHex(hex string, n int) (string, int, error) {
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || v > utf8.MaxRune {
+		return "", 0, fmt.Errorf("invalid hexadecimal escape %q", hex)
+	}
+	return string(rune(v)), n, nil
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
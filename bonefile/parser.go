@@ -0,0 +1,281 @@
+package bonefile
+
+import (
+	"strings"
+)
+
+// Mode controls optional parser functionality.
+type Mode uint
+
+const (
+	// PerlEscapes decodes backslash escapes in the heredoc body as Perl
+	// does in double-quoted strings, such as \t, \e, \033 and \x{263A}.
+	// It is used for the original cowfiles.
+	//
+	// Without it, only \\, \@ and \$ are decoded and any other backslash
+	// is kept as it is.
+	PerlEscapes Mode = 1 << iota
+)
+
+// Parse parses the bonefile src.
+// name is used as the filename in error messages.
+//
+// If src is malformed, it returns *Error which reports the position.
+func Parse(name string, src []byte, mode Mode) (*File, error) {
+	p := &parser{
+		file:  &File{Name: name},
+		lines: strings.Split(string(src), "\n"),
+		mode:  mode,
+	}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.file, nil
+}
+
+// ParseBody parses src as the heredoc body of a bonefile, that is,
+// the art without the header and the heredoc delimiters.
+// Positions in errors are relative to src.
+func ParseBody(name string, src []byte, mode Mode) (*File, error) {
+	p := &parser{
+		file: &File{Name: name},
+		mode: mode,
+	}
+	pos := Pos{Line: 1, Column: 1}
+	segments, err := p.scanBody(string(src), pos, true)
+	if err != nil {
+		return nil, err
+	}
+	p.file.Body = &Body{
+		Pos:         pos,
+		Interpolate: true,
+		Segments:    segments,
+	}
+	return p.file, nil
+}
+
+type parser struct {
+	file  *File
+	lines []string
+	// idx is the index of the current line in lines.
+	idx  int
+	mode Mode
+}
+
+func (p *parser) pos(column int) Pos {
+	return Pos{Line: p.idx + 1, Column: column}
+}
+
+func (p *parser) parse() error {
+	for ; p.idx < len(p.lines); p.idx++ {
+		line := strings.TrimRight(p.lines[p.idx], " \t\r")
+		text := strings.TrimLeft(line, " \t")
+		col := len(line) - len(text) + 1
+		switch {
+		case text == "":
+			continue
+		case strings.HasPrefix(text, "#"):
+			if p.file.Body != nil {
+				continue
+			}
+			p.file.Comments = append(p.file.Comments, &Comment{
+				Pos:  p.pos(col),
+				Text: text,
+			})
+		case p.file.Body != nil:
+			return p.file.errorf(p.pos(col), "unexpected %q after heredoc", text)
+		case strings.HasPrefix(text, "$"):
+			if err := p.parseAssignment(text, col); err != nil {
+				return err
+			}
+		default:
+			return p.file.errorf(p.pos(col), "unexpected %q", text)
+		}
+	}
+	if p.file.Body == nil {
+		return p.file.errorf(Pos{Line: len(p.lines), Column: 1}, "missing heredoc such as \"$the_bone = <<EOB;\"")
+	}
+	return nil
+}
+
+// parseAssignment parses `$name = value;` or `$name = <<EOB;`.
+// text starts with "$" and col is the column of it.
+func (p *parser) parseAssignment(text string, col int) error {
+	start := col
+	text, col = text[1:], col+1
+	name := scanIdent(text)
+	if name == "" {
+		return p.file.errorf(p.pos(col), "expected variable name after \"$\"")
+	}
+	text, col = text[len(name):], col+len(name)
+	text, col = skipSpaces(text, col)
+	if !strings.HasPrefix(text, "=") {
+		return p.file.errorf(p.pos(col), "expected \"=\" after $%s", name)
+	}
+	text, col = skipSpaces(text[1:], col+1)
+	value := strings.TrimRight(strings.TrimSuffix(text, ";"), " \t")
+	if value == "" {
+		return p.file.errorf(p.pos(col), "missing value for $%s", name)
+	}
+	if strings.HasPrefix(value, "<<") {
+		return p.parseHeredoc(name, value[2:], start, col+2)
+	}
+	p.file.Directives = append(p.file.Directives, &Directive{
+		Pos:      p.pos(start),
+		Name:     name,
+		ValuePos: p.pos(col),
+		Value:    value,
+	})
+	return nil
+}
+
+// parseHeredoc parses the delimiter of the heredoc and its body.
+// delim is the text after "<<" and col is the column of it.
+func (p *parser) parseHeredoc(name, delim string, start, col int) error {
+	interpolate := true
+	if n := len(delim); n >= 2 && (delim[0] == '"' || delim[0] == '\'') && delim[n-1] == delim[0] {
+		interpolate = delim[0] == '"'
+		delim, col = delim[1:n-1], col+1
+	}
+	if ident := scanIdent(delim); ident == "" || ident != delim {
+		return p.file.errorf(p.pos(col), "invalid heredoc delimiter %q", delim)
+	}
+	body := &Body{
+		Pos:         p.pos(start),
+		Name:        name,
+		Delimiter:   delim,
+		Interpolate: interpolate,
+	}
+	first := p.idx + 1
+	for p.idx = first; p.idx < len(p.lines); p.idx++ {
+		if strings.TrimRight(p.lines[p.idx], " \t\r") == delim {
+			segments, err := p.scanBody(
+				strings.Join(p.lines[first:p.idx], "\n"),
+				Pos{Line: first + 1, Column: 1},
+				interpolate,
+			)
+			if err != nil {
+				return err
+			}
+			body.Segments = segments
+			p.file.Body = body
+			return nil
+		}
+	}
+	return p.file.errorf(body.Pos, "unterminated heredoc, missing %q", delim)
+}
+
+// scanBody splits the heredoc body into texts and variable references.
+// If interpolate is false, the whole body is a text.
+func (p *parser) scanBody(src string, pos Pos, interpolate bool) ([]Segment, error) {
+	segments := make([]Segment, 0)
+	if !interpolate {
+		if src != "" {
+			segments = append(segments, &Text{ValuePos: pos, Value: src})
+		}
+		return segments, nil
+	}
+
+	unescape := unescapeBone
+	if p.mode&PerlEscapes != 0 {
+		unescape = unescapePerl
+	}
+
+	var (
+		text    strings.Builder
+		textPos = pos
+	)
+	flush := func() {
+		if text.Len() > 0 {
+			segments = append(segments, &Text{ValuePos: textPos, Value: text.String()})
+			text.Reset()
+		}
+	}
+	advance := func(s string) {
+		for i := 0; i < len(s); i++ {
+			if s[i] == '\n' {
+				pos.Line++
+				pos.Column = 1
+			} else {
+				pos.Column++
+			}
+		}
+	}
+
+	for i := 0; i < len(src); {
+		if text.Len() == 0 {
+			textPos = pos
+		}
+		c := src[i]
+		switch c {
+		case '\\':
+			decoded, n, err := unescape(src[i:])
+			if err != nil {
+				return nil, p.file.errorf(pos, "%s", err)
+			}
+			if n == 0 {
+				text.WriteByte(c)
+				advance(src[i : i+1])
+				i++
+				continue
+			}
+			text.WriteString(decoded)
+			advance(src[i : i+n])
+			i += n
+		case '$':
+			name, n, braced := scanVariable(src[i+1:])
+			if name == "" {
+				text.WriteByte(c)
+				advance(src[i : i+1])
+				i++
+				continue
+			}
+			flush()
+			segments = append(segments, &Variable{
+				NamePos: pos,
+				Name:    name,
+				Braced:  braced,
+			})
+			advance(src[i : i+1+n])
+			i += 1 + n
+		default:
+			text.WriteByte(c)
+			advance(src[i : i+1])
+			i++
+		}
+	}
+	flush()
+	return segments, nil
+}
+
+// scanVariable scans "name" or "{name}" at the beginning of s.
+// It returns the name and the length of the consumed bytes.
+// If s does not start with a variable name, name is empty.
+func scanVariable(s string) (name string, n int, braced bool) {
+	if strings.HasPrefix(s, "{") {
+		name := scanIdent(s[1:])
+		if name == "" || !strings.HasPrefix(s[1+len(name):], "}") {
+			return "", 0, false
+		}
+		return name, len(name) + 2, true
+	}
+	name = scanIdent(s)
+	return name, len(name), false
+}
+
+// scanIdent returns the identifier at the beginning of s.
+func scanIdent(s string) string {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || i > 0 && '0' <= c && c <= '9' {
+			continue
+		}
+		return s[:i]
+	}
+	return s
+}
+
+func skipSpaces(s string, col int) (string, int) {
+	trimmed := strings.TrimLeft(s, " \t")
+	return trimmed, col + len(s) - len(trimmed)
+}
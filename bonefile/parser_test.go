@@ -0,0 +1,221 @@
+package bonefile
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	src := `##
+## Bone
+##
+$ballonOffset = 57
+$the_bone = <<EOB;
+ $thoughts (${eyes})\\_
+  \$tongue \@ $
+EOB
+`
+	got, err := Parse("test.bone", []byte(src), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &File{
+		Name: "test.bone",
+		Comments: []*Comment{
+			{Pos: Pos{Line: 1, Column: 1}, Text: "##"},
+			{Pos: Pos{Line: 2, Column: 1}, Text: "## Bone"},
+			{Pos: Pos{Line: 3, Column: 1}, Text: "##"},
+		},
+		Directives: []*Directive{
+			{
+				Pos:      Pos{Line: 4, Column: 1},
+				Name:     "ballonOffset",
+				ValuePos: Pos{Line: 4, Column: 17},
+				Value:    "57",
+			},
+		},
+		Body: &Body{
+			Pos:         Pos{Line: 5, Column: 1},
+			Name:        "the_bone",
+			Delimiter:   "EOB",
+			Interpolate: true,
+			Segments: []Segment{
+				&Text{ValuePos: Pos{Line: 6, Column: 1}, Value: " "},
+				&Variable{NamePos: Pos{Line: 6, Column: 2}, Name: "thoughts"},
+				&Text{ValuePos: Pos{Line: 6, Column: 11}, Value: " ("},
+				&Variable{NamePos: Pos{Line: 6, Column: 13}, Name: "eyes", Braced: true},
+				&Text{ValuePos: Pos{Line: 6, Column: 20}, Value: ")\\_\n  $tongue @ $"},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want, +got)\n%s", diff)
+	}
+
+	expanded, err := got.Expand(map[string]string{
+		"thoughts": "o",
+		"eyes":     "oo",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantExpanded := " o (oo)\\_\n  $tongue @ $"
+	if wantExpanded != expanded {
+		t.Errorf("want %q, but got %q", wantExpanded, expanded)
+	}
+}
+
+func TestParse_quotedDelimiter(t *testing.T) {
+	src := "$the_cow = <<'EOC';\n $eyes \\\\\nEOC\n"
+	got, err := Parse("", []byte(src), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Body.Interpolate {
+		t.Error("want not interpolated")
+	}
+	expanded, err := got.Expand(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := " $eyes \\\\"; want != expanded {
+		t.Errorf("want %q, but got %q", want, expanded)
+	}
+}
+
+func TestParse_error(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want *Error
+	}{
+		{
+			name: "missing heredoc",
+			src:  "## comment\n$ballonOffset = 1\n",
+			want: &Error{
+				Filename: "error.bone",
+				Pos:      Pos{Line: 3, Column: 1},
+				Msg:      `missing heredoc such as "$the_bone = <<EOB;"`,
+			},
+		},
+		{
+			name: "unterminated heredoc",
+			src:  "$the_bone = <<EOB;\n  $thoughts\n",
+			want: &Error{
+				Filename: "error.bone",
+				Pos:      Pos{Line: 1, Column: 1},
+				Msg:      `unterminated heredoc, missing "EOB"`,
+			},
+		},
+		{
+			name: "missing equal",
+			src:  "  $ballonOffset 1\n",
+			want: &Error{
+				Filename: "error.bone",
+				Pos:      Pos{Line: 1, Column: 17},
+				Msg:      `expected "=" after $ballonOffset`,
+			},
+		},
+		{
+			name: "missing value",
+			src:  "$ballonOffset = ;\n",
+			want: &Error{
+				Filename: "error.bone",
+				Pos:      Pos{Line: 1, Column: 17},
+				Msg:      `missing value for $ballonOffset`,
+			},
+		},
+		{
+			name: "invalid delimiter",
+			src:  "$the_bone = <<E-B;\nE-B\n",
+			want: &Error{
+				Filename: "error.bone",
+				Pos:      Pos{Line: 1, Column: 15},
+				Msg:      `invalid heredoc delimiter "E-B"`,
+			},
+		},
+		{
+			name: "unexpected text",
+			src:  "hello\n$the_bone = <<EOB;\nEOB\n",
+			want: &Error{
+				Filename: "error.bone",
+				Pos:      Pos{Line: 1, Column: 1},
+				Msg:      `unexpected "hello"`,
+			},
+		},
+		{
+			name: "unexpected text after heredoc",
+			src:  "$the_bone = <<EOB;\nEOB\n  hello\n",
+			want: &Error{
+				Filename: "error.bone",
+				Pos:      Pos{Line: 3, Column: 3},
+				Msg:      `unexpected "hello" after heredoc`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse("error.bone", []byte(tt.src), 0)
+			var got *Error
+			if !errors.As(err, &got) {
+				t.Fatalf("want *Error, but got %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("(-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFile_Expand_undefined(t *testing.T) {
+	f, err := Parse("test.bone", []byte("$the_bone = <<EOB;\n\n  $hat\nEOB\n"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.Expand(map[string]string{})
+	want := "test.bone:3:3: undefined variable $hat"
+	if err == nil || err.Error() != want {
+		t.Fatalf("want %q, but got %v", want, err)
+	}
+}
+
+func TestParse_perlEscapes(t *testing.T) {
+	src := "$the_cow = <<\"EOC\";\n\\\\ \\@ \\$ \\t \\e[0m \\033 \\x41 \\x{263A} \\N{U+2603} \\cA \\/\nEOC\n"
+	f, err := Parse("test.cow", []byte(src), PerlEscapes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := f.Expand(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "\\ @ $ \t \x1b[0m \x1b A \u263a \u2603 \x01 /"
+	if want != got {
+		t.Errorf("want %q, but got %q", want, got)
+	}
+
+	_, err = Parse("test.cow", []byte("$the_cow = <<EOC;\n  \\Uupper\nEOC\n"), PerlEscapes)
+	wantErr := `test.cow:2:3: unsupported escape \U`
+	if err == nil || err.Error() != wantErr {
+		t.Fatalf("want %q, but got %v", wantErr, err)
+	}
+}
+
+func TestParseBody(t *testing.T) {
+	f, err := ParseBody("art", []byte("  $thoughts\n   ($eyes)\\\\"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"thoughts", "eyes"}, f.Body.Variables()); diff != "" {
+		t.Errorf("(-want, +got)\n%s", diff)
+	}
+	got, err := f.Expand(map[string]string{"thoughts": "o", "eyes": "oo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "  o\n   (oo)\\"; want != got {
+		t.Errorf("want %q, but got %q", want, got)
+	}
+}
@@ -1,20 +1,14 @@
 package bonesay
 
 import (
-	"io/ioutil"
-	"math/rand"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
-	"time"
 )
 
-func init() {
-	rand.Seed(time.Now().UTC().UnixNano())
-}
-
 // Say to return bonesay string.
 func Say(phrase string, options ...Option) (string, error) {
 	bone, err := New(options...)
@@ -33,8 +27,33 @@ const (
 
 	// InDirectory indicates the BONEPATH in your directory.
 	InDirectory
+
+	// InSource indicates the BoneSource which is specified by WithSources.
+	InSource
+
+	// InMemory indicates the bonefiles which are registered by Register.
+	InMemory
+)
+
+// Format indicates the format of the bonefile.
+type Format int
+
+const (
+	// BoneFormat indicates the ".bone" file.
+	BoneFormat Format = iota
+
+	// CowFormat indicates the original Perl ".cow" file.
+	CowFormat
 )
 
+// Suffix returns the file suffix of the format.
+func (f Format) Suffix() string {
+	if f == CowFormat {
+		return ".cow"
+	}
+	return ".bone"
+}
+
 // BonePath is information of the BONEPATH.
 type BonePath struct {
 	// Name is name of the BONEPATH.
@@ -42,12 +61,22 @@ type BonePath struct {
 	Name string
 	// BoneFiles are name of the bonefile which are trimmed ".bone" suffix.
 	BoneFiles []string
+	// CowFiles are name of the original cowfile which are trimmed ".cow" suffix.
+	// The name which is also in BoneFiles is not included.
+	CowFiles []string
 	// LocationType is the type of BONEPATH
 	LocationType LocationType
+	// Source is the source of the bonefiles.
+	Source BoneSource
+	// Err is the error which is occurred while reading the source.
+	// If it is not nil, the source is skipped and there are no bonefiles.
+	Err error
 }
 
 // Lookup will look for the target bonefile in the specified path.
 // If it exists, it returns the bonefile information and true value.
+//
+// ".bone" file takes precedence over ".cow" file of the same name.
 func (c *BonePath) Lookup(target string) (*BoneFile, bool) {
 	for _, bonefile := range c.BoneFiles {
 		if bonefile == target {
@@ -55,12 +84,33 @@ func (c *BonePath) Lookup(target string) (*BoneFile, bool) {
 				Name:         bonefile,
 				BasePath:     c.Name,
 				LocationType: c.LocationType,
+				Source:       c.Source,
+			}, true
+		}
+	}
+	for _, cowfile := range c.CowFiles {
+		if cowfile == target {
+			return &BoneFile{
+				Name:         cowfile,
+				BasePath:     c.Name,
+				LocationType: c.LocationType,
+				Source:       c.Source,
+				Format:       CowFormat,
 			}, true
 		}
 	}
 	return nil, false
 }
 
+// Names returns the names of all bonefiles and cowfiles in the path.
+func (c *BonePath) Names() []string {
+	names := make([]string, 0, len(c.BoneFiles)+len(c.CowFiles))
+	names = append(names, c.BoneFiles...)
+	names = append(names, c.CowFiles...)
+	sort.Strings(names)
+	return names
+}
+
 // BoneFile is information of the bonefile.
 type BoneFile struct {
 	// Name is name of the bonefile.
@@ -69,100 +119,197 @@ type BoneFile struct {
 	BasePath string
 	// LocationType is the type of BONEPATH
 	LocationType LocationType
+	// Source is the source of the bonefile.
+	// If it is nil, the source is determined by LocationType and BasePath.
+	Source BoneSource
+	// Format is the format of the bonefile.
+	Format Format
 }
 
-// ReadAll reads the bonefile content.
-// If LocationType is InBinary, the file read from binary.
-// otherwise reads from file system.
+// ReadAll reads the bonefile content from the source.
 func (c *BoneFile) ReadAll() ([]byte, error) {
-	joinedPath := filepath.Join(c.BasePath, c.Name+".bone")
+	return fs.ReadFile(c.source(), c.Name+c.Format.Suffix())
+}
+
+func (c *BoneFile) source() BoneSource {
+	if c.Source != nil {
+		return c.Source
+	}
 	if c.LocationType == InBinary {
-		return Asset(joinedPath)
+		return BinarySource()
 	}
-	return ioutil.ReadFile(joinedPath)
+	return DirSource(c.BasePath)
+}
+
+func (c *BoneFile) path() string {
+	return filepath.Join(c.BasePath, c.Name+c.Format.Suffix())
 }
 
 // Bones to get list of bones
 func Bones() ([]*BonePath, error) {
-	bonePaths, err := bonesFromBonePath()
-	if err != nil {
-		return nil, err
-	}
-	bonePaths = append(bonePaths, &BonePath{
-		Name:         "bones",
-		BoneFiles:    BonesInBinary(),
-		LocationType: InBinary,
-	})
-	return bonePaths, nil
-}
-
-func bonesFromBonePath() ([]*BonePath, error) {
-	bonePaths := make([]*BonePath, 0)
-	bonePath := os.Getenv("BONEATH")
-	if bonePath == "" {
-		return bonePaths, nil
-	}
-	paths := splitPath(bonePath)
-	for _, path := range paths {
-		dirEntries, err := ioutil.ReadDir(path)
-		if err != nil {
-			return nil, err
+	return BonesIn(Sources()...)
+}
+
+// Sources returns the default search path of bonefiles.
+//
+// The sources are searched in the following order, and the first
+// bonefile found shadows the others of the same name:
+//
+//  1. the bonefiles registered by Register
+//  2. the directories in BONEPATH env
+//  3. $XDG_DATA_HOME/bonesay/bones (default: ~/.local/share/bonesay/bones)
+//  4. bonesay/bones in $XDG_DATA_DIRS (default: /usr/local/share/bonesay/bones
+//     and /usr/share/bonesay/bones)
+//  5. the bonefiles in binary
+//
+// The XDG directories are only included if they exist.
+func Sources() []BoneSource {
+	sources := make([]BoneSource, 0)
+	if registry.len() > 0 {
+		sources = append(sources, registry)
+	}
+	if bonePath := os.Getenv("BONEPATH"); bonePath != "" {
+		for _, path := range splitPath(bonePath) {
+			if path != "" {
+				sources = append(sources, DirSource(path))
+			}
 		}
-		path := &BonePath{
-			Name:         path,
-			BoneFiles:    []string{},
-			LocationType: InDirectory,
+	}
+	for _, dir := range xdgDataDirs() {
+		path := filepath.Join(dir, "bonesay", "bones")
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			sources = append(sources, DirSource(path))
 		}
-		for _, entry := range dirEntries {
-			name := entry.Name()
-			if strings.HasSuffix(name, ".bone") {
-				name = strings.TrimSuffix(name, ".bone")
-				path.BoneFiles = append(path.BoneFiles, name)
-			}
+	}
+	return append(sources, BinarySource())
+}
+
+// xdgDataDirs returns $XDG_DATA_HOME and $XDG_DATA_DIRS in order of precedence.
+func xdgDataDirs() []string {
+	dirs := make([]string, 0)
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		dirs = append(dirs, dataHome)
+	} else if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".local", "share"))
+	}
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share/:/usr/share/"
+	}
+	for _, dir := range filepath.SplitList(dataDirs) {
+		if dir != "" {
+			dirs = append(dirs, dir)
 		}
-		sort.Strings(path.BoneFiles)
-		bonePaths = append(bonePaths, path)
 	}
-	return bonePaths, nil
+	return dirs
 }
 
 // GetBone to get bone's ascii art
+//
+// If the bonefile is malformed, it returns *bonefile.Error.
 func (bone *Bone) GetBone() (string, error) {
-	src, err := bone.typ.ReadAll()
+	a, err := bone.loadArt()
 	if err != nil {
 		return "", err
 	}
+	return a.text, nil
+}
 
-	r := strings.NewReplacer(
-		"\\\\", "\\",
-		"\\@", "@",
-		"\\$", "$",
-		"$eyes", bone.eyes,
-		"${eyes}", bone.eyes,
-		"$tongue", bone.tongue,
-		"${tongue}", bone.tongue,
-		"$thoughts", string(bone.thoughts),
-		"${thoughts}", string(bone.thoughts),
-	)
-	newsrc := r.Replace(string(src))
-	separate := strings.Split(newsrc, "\n")
-	mow := make([]string, 0, len(separate))
-	for _, line := range separate {
-		if strings.Contains(line, "$the_bone = <<EOB") || strings.HasPrefix(line, "##") {
-			continue
+// defaultBalloonOffset is used if the bonefile does not specify $ballonOffset.
+// The classic layout, which is used by cowfiles, puts the balloon one column
+// right to the bone.
+const defaultBalloonOffset = 1
+
+// defaultFaceWidth is the width of $eyes and $tongue if the bonefile does
+// not declare $eyes_width or $tongue_width.
+const defaultFaceWidth = 2
+
+// art is the bone's ascii art which is loaded for a single rendering.
+type art struct {
+	text          string
+	balloonOffset int
+	// position is where the balloon is placed. It is not BalloonDefault.
+	position BalloonPosition
+}
+
+// loadArt reads the bonefile and expands it with the bone's settings.
+// It does not modify the bone, so it is safe for concurrent use.
+//
+// Unless the balloon is above the bone, the tail in the bonefile is
+// removed from the art.
+func (bone *Bone) loadArt() (*art, error) {
+	t, err := templates.load(bone.typ)
+	if err != nil {
+		return nil, artError(bone.typ, err)
+	}
+	position := bone.balloonPosition
+	if position == BalloonDefault {
+		position = t.balloonPosition
+	}
+	if position == BalloonDefault {
+		position = BalloonAbove
+	}
+	thoughts := string(bone.thoughts)
+	if bone.typ.Format == CowFormat && !bone.thoughtsSet {
+		thoughts = `\`
+		if bone.thinking {
+			thoughts = "o"
 		}
+	}
+	if position != BalloonAbove {
+		thoughts = " "
+	}
+	eyes, ok := adjustToWidth(bone.eyes, t.eyesWidth)
+	if !ok {
+		return nil, fmt.Errorf("eyes %q do not fit in %d columns of %s", bone.eyes, t.eyesWidth, bone.typ.Name)
+	}
+	tongue, ok := adjustToWidth(bone.tongue, t.tongueWidth)
+	if !ok {
+		return nil, fmt.Errorf("tongue %q does not fit in %d columns of %s", bone.tongue, t.tongueWidth, bone.typ.Name)
+	}
+	vars := bone.expandVars(t.vars)
+	vars["eyes"] = eyes
+	vars["tongue"] = tongue
+	vars["thoughts"] = thoughts
+	text, err := t.file.Expand(vars)
+	if err != nil {
+		return nil, artError(bone.typ, err)
+	}
+	if position != BalloonAbove {
+		text = trimArt(text, position != BalloonBelow)
+	}
+	return &art{
+		text:          text,
+		balloonOffset: t.balloonOffset,
+		position:      position,
+	}, nil
+}
 
-		if strings.Contains(line, "$ballonOffset = ") {
-			line = strings.TrimPrefix(line, "$ballonOffset = ")
-			bone.balloonOffset, _ = strconv.Atoi(line)
+// trimArt removes the blank lines at the top of text and the spaces at the
+// end of each line, which are left by the tail. If dedent is true, the
+// indentation which is common to all lines is removed as well.
+func trimArt(text string, dedent bool) string {
+	lines := strings.Split(text, "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	indent := -1
+	for i, line := range lines {
+		line = strings.TrimRight(line, " ")
+		lines[i] = line
+		if line == "" {
 			continue
 		}
-
-		if strings.HasPrefix(line, "EOB") {
-			break
+		if n := len(line) - len(strings.TrimLeft(line, " ")); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if dedent && indent > 0 {
+		for i, line := range lines {
+			if line != "" {
+				lines[i] = line[indent:]
+			}
 		}
-
-		mow = append(mow, line)
 	}
-	return strings.Join(mow, "\n"), nil
+	return strings.Join(lines, "\n")
 }
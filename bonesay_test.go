@@ -3,16 +3,29 @@ package bonesay
 import (
 	"bytes"
 	"errors"
+	"io/fs"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/anthonycuervo23/bonesay/v2/bonefile"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
+// isolateXDG makes the XDG data directories empty.
+func isolateXDG(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "home"))
+	t.Setenv("XDG_DATA_DIRS", filepath.Join(dir, "local")+string(filepath.ListSeparator)+filepath.Join(dir, "share"))
+	return dir
+}
+
 func TestBones(t *testing.T) {
+	isolateXDG(t)
+
 	t.Run("no set BONEPATH env", func(t *testing.T) {
 		bonePaths, err := Bones()
 		if err != nil {
@@ -29,6 +42,7 @@ func TestBones(t *testing.T) {
 		wantBonePath := &BonePath{
 			Name:         "bones",
 			LocationType: InBinary,
+			Source:       BinarySource(),
 		}
 		if diff := cmp.Diff(wantBonePath, bonePath,
 			cmpopts.IgnoreFields(BonePath{}, "BoneFiles"),
@@ -55,10 +69,12 @@ func TestBones(t *testing.T) {
 			{
 				Name:         "testdata/testdir",
 				LocationType: InDirectory,
+				Source:       DirSource(bonepath),
 			},
 			{
 				Name:         "bones",
 				LocationType: InBinary,
+				Source:       BinarySource(),
 			},
 		}
 		if diff := cmp.Diff(wants, bonePaths,
@@ -78,18 +94,94 @@ func TestBones(t *testing.T) {
 		}
 	})
 
-	t.Run("set BONEPATH env", func(t *testing.T) {
+	t.Run("unreadable directory in BONEPATH env", func(t *testing.T) {
 		os.Setenv("BONEPATH", "notfound")
 		defer os.Unsetenv("BONEPATH")
 
-		_, err := Bones()
-		if err == nil {
-			t.Fatal("want error")
+		bonePaths, err := Bones()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(bonePaths) != 2 {
+			t.Fatalf("want 2, but got %d", len(bonePaths))
+		}
+		if !errors.Is(bonePaths[0].Err, fs.ErrNotExist) {
+			t.Fatalf("want fs.ErrNotExist, but got %v", bonePaths[0].Err)
+		}
+		if len(bonePaths[0].Names()) != 0 {
+			t.Fatalf("want no bonefiles, but got %v", bonePaths[0].Names())
+		}
+		if bonePaths[1].LocationType != InBinary {
+			t.Fatalf("want %v, but got %v", InBinary, bonePaths[1].LocationType)
 		}
 	})
 
 }
 
+func TestSources(t *testing.T) {
+	dir := isolateXDG(t)
+	dataHome := filepath.Join(dir, "home", "bonesay", "bones")
+	dataDir := filepath.Join(dir, "share", "bonesay", "bones")
+	for _, d := range []string{dataHome, dataDir} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+		src, err := ioutil.ReadFile(filepath.Join("testdata", "testdir", "test.bone"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(d, "test.bone"), src, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bonepath := filepath.Join("testdata", "testdir")
+	t.Setenv("BONEPATH", bonepath)
+
+	want := []BoneSource{
+		DirSource(bonepath),
+		DirSource(dataHome),
+		DirSource(dataDir),
+		BinarySource(),
+	}
+	if diff := cmp.Diff(want, Sources()); diff != "" {
+		t.Errorf("(-want, +got)\n%s", diff)
+	}
+
+	bonePaths, err := Bones()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := Resolve(bonePaths, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.BoneFile.BasePath != bonepath {
+		t.Errorf("want %q, but got %q", bonepath, r.BoneFile.BasePath)
+	}
+	shadowed := make([]string, 0, len(r.Shadowed))
+	for _, bonefile := range r.Shadowed {
+		shadowed = append(shadowed, bonefile.BasePath)
+	}
+	if diff := cmp.Diff([]string{dataHome, dataDir}, shadowed); diff != "" {
+		t.Errorf("(-want, +got)\n%s", diff)
+	}
+
+	bone, err := New(Type("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := bone.BoneFile().BasePath; got != bonepath {
+		t.Errorf("want %q, but got %q", bonepath, got)
+	}
+
+	_, err = Resolve(bonePaths, "unknown")
+	var notfound *NotFound
+	if !errors.As(err, &notfound) {
+		t.Errorf("want *NotFound, but got %v", err)
+	}
+}
+
 func TestBonePath_Lookup(t *testing.T) {
 	t.Run("looked for bonefile", func(t *testing.T) {
 		c := &BonePath{
@@ -112,6 +204,29 @@ func TestBonePath_Lookup(t *testing.T) {
 		}
 	})
 
+	t.Run("looked for cowfile", func(t *testing.T) {
+		c := &BonePath{
+			Name:         "basepath",
+			BoneFiles:    []string{"test"},
+			CowFiles:     []string{"tux"},
+			LocationType: InDirectory,
+		}
+		got, ok := c.Lookup("tux")
+		if !ok {
+			t.Errorf("want %v", ok)
+		}
+		want := &BoneFile{
+			Name:         "tux",
+			BasePath:     "basepath",
+			LocationType: InDirectory,
+			Format:       CowFormat,
+		}
+
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("(-want, +got)\n%s", diff)
+		}
+	})
+
 	t.Run("no bonefile", func(t *testing.T) {
 		c := &BonePath{
 			Name:         "basepath",
@@ -129,12 +244,12 @@ func TestBonePath_Lookup(t *testing.T) {
 }
 
 func TestBoneFile_ReadAll(t *testing.T) {
-	fromTestData := &BoneFile{
-		Name:         "test",
-		BasePath:     filepath.Join("testdata", "testdir"),
+	fromDirectory := &BoneFile{
+		Name:         "default",
+		BasePath:     "bones",
 		LocationType: InDirectory,
 	}
-	fromTestdataContent, err := fromTestData.ReadAll()
+	fromDirectoryContent, err := fromDirectory.ReadAll()
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -149,12 +264,89 @@ func TestBoneFile_ReadAll(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if !bytes.Equal(fromTestdataContent, fromBinaryContent) {
-		t.Fatalf("testdata\n%s\n\nbinary%s\n", string(fromTestdataContent), string(fromBinaryContent))
+	if !bytes.Equal(fromDirectoryContent, fromBinaryContent) {
+		t.Fatalf("directory\n%s\n\nbinary%s\n", string(fromDirectoryContent), string(fromBinaryContent))
 	}
 
 }
 
+func TestBone_GetBone(t *testing.T) {
+	t.Run("testdata", func(t *testing.T) {
+		bone, err := New(Eyes("^^"), Tongue("U"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		bone.typ = &BoneFile{
+			Name:         "test",
+			BasePath:     filepath.Join("testdata", "testdir"),
+			LocationType: InDirectory,
+		}
+		got, err := bone.GetBone()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `        /   ^__^
+         /  (^^)\_______
+            (__)\       )\/\
+             U  ||----w |
+                ||     ||`
+		if want != got {
+			t.Fatalf("want\n%s\n\ngot\n%s", want, got)
+		}
+	})
+
+	t.Run("cowfile", func(t *testing.T) {
+		bone, err := New(Thoughts('o'))
+		if err != nil {
+			t.Fatal(err)
+		}
+		bone.typ = &BoneFile{
+			Name:         "tux",
+			BasePath:     filepath.Join("testdata", "cows"),
+			LocationType: InDirectory,
+			Format:       CowFormat,
+		}
+		got, err := bone.GetBone()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `   o
+    o
+        .--.
+       |o_o |
+       |:_/ |
+      //   \ \
+     (|     | )
+    /'\_   _/` + "`" + `\
+    \___)=(___/
+`
+		if want != got {
+			t.Fatalf("want\n%s\n\ngot\n%s", want, got)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		bone, err := New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		bone.typ = &BoneFile{
+			Name:         "broken",
+			BasePath:     filepath.Join("testdata", "broken"),
+			LocationType: InDirectory,
+		}
+		_, err = bone.GetBone()
+		var bferr *bonefile.Error
+		if !errors.As(err, &bferr) {
+			t.Fatalf("want *bonefile.Error, but got %v", err)
+		}
+		want := bonefile.Pos{Line: 2, Column: 17}
+		if bferr.Pos != want {
+			t.Fatalf("want %v, but got %v", want, bferr.Pos)
+		}
+	})
+}
+
 const defaultSay = ` ________ 
 < bonesay >
  -------- 
@@ -0,0 +1,176 @@
+package bonesay
+
+import (
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anthonycuervo23/bonesay/v2/bonefile"
+)
+
+// maxTemplates is the maximum number of cached templates.
+// When the cache is full, it is cleared.
+const maxTemplates = 1024
+
+// templates is the cache of parsed bonefiles which is shared by all bones.
+var templates = newTemplateCache()
+
+// template is a parsed bonefile.
+type template struct {
+	file            *bonefile.File
+	balloonOffset   int
+	balloonPosition BalloonPosition
+	// eyesWidth and tongueWidth are the widths of $eyes and $tongue.
+	eyesWidth   int
+	tongueWidth int
+	// vars are the variables which are declared in the header.
+	vars map[string]*templateVar
+
+	// modTime and size are of the bonefile when it was parsed.
+	modTime time.Time
+	size    int64
+}
+
+type templateKey struct {
+	source BoneSource
+	name   string
+}
+
+// templateCache caches parsed bonefiles. The cached template is used while
+// the modification time and the size of the bonefile are unchanged.
+type templateCache struct {
+	mu      sync.RWMutex
+	entries map[templateKey]*template
+}
+
+func newTemplateCache() *templateCache {
+	return &templateCache{
+		entries: make(map[templateKey]*template),
+	}
+}
+
+func (c *templateCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[templateKey]*template)
+}
+
+// load returns the parsed bonefile. It parses the bonefile only if it is
+// not cached or modified since it was cached.
+func (c *templateCache) load(bf *BoneFile) (*template, error) {
+	source := bf.source()
+	name := bf.Name + bf.Format.Suffix()
+	info, err := fs.Stat(source, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// A source which cannot be a map key is never cached.
+	cacheable := isComparable(reflect.ValueOf(source))
+	key := templateKey{source: source, name: name}
+	if cacheable {
+		c.mu.RLock()
+		t, ok := c.entries[key]
+		c.mu.RUnlock()
+		if ok && t.modTime.Equal(info.ModTime()) && t.size == info.Size() {
+			return t, nil
+		}
+	}
+
+	t, err := parseTemplate(bf)
+	if err != nil {
+		return nil, err
+	}
+	t.modTime, t.size = info.ModTime(), info.Size()
+
+	if cacheable {
+		c.mu.Lock()
+		if len(c.entries) >= maxTemplates {
+			c.entries = make(map[templateKey]*template)
+		}
+		c.entries[key] = t
+		c.mu.Unlock()
+	}
+	return t, nil
+}
+
+// isComparable reports whether v can be a map key without panic. Unlike
+// reflect.Type.Comparable, it checks the dynamic values of interfaces, such
+// as fs.FS in a struct which may be fstest.MapFS.
+func isComparable(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Interface:
+		return v.IsNil() || isComparable(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !isComparable(v.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !isComparable(v.Index(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	return v.Type().Comparable()
+}
+
+func parseTemplate(bf *BoneFile) (*template, error) {
+	src, err := bf.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	var mode bonefile.Mode
+	if bf.Format == CowFormat {
+		mode = bonefile.PerlEscapes
+	}
+	f, err := bonefile.Parse(bf.path(), src, mode)
+	if err != nil {
+		return nil, err
+	}
+	t := &template{
+		file:          f,
+		balloonOffset: defaultBalloonOffset,
+	}
+	if d := f.Directive("ballonOffset"); d != nil {
+		offset, err := strconv.Atoi(d.Value)
+		if err != nil {
+			return nil, &bonefile.Error{
+				Filename: f.Name,
+				Pos:      d.ValuePos,
+				Msg:      fmt.Sprintf("invalid $ballonOffset %q", d.Value),
+			}
+		}
+		t.balloonOffset = offset
+	}
+	if d := f.Directive("balloonPosition"); d != nil {
+		position, ok := balloonPositions[strings.Trim(d.Value, `"'`)]
+		if !ok {
+			return nil, &bonefile.Error{
+				Filename: f.Name,
+				Pos:      d.ValuePos,
+				Msg:      fmt.Sprintf("invalid $balloonPosition %q", d.Value),
+			}
+		}
+		t.balloonPosition = position
+	}
+	if t.eyesWidth, err = parseWidth(f, "eyes", defaultFaceWidth); err != nil {
+		return nil, err
+	}
+	if t.tongueWidth, err = parseWidth(f, "tongue", defaultFaceWidth); err != nil {
+		return nil, err
+	}
+	t.vars, err = parseVars(f)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
@@ -0,0 +1,148 @@
+package bonesay
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestTemplateCache(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cached.bone")
+	write := func(art string, modTime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte("$the_bone = <<EOB;\n"+art+"\nEOB\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	say := func() string {
+		t.Helper()
+		bone, err := New(WithSources(DirSource(dir)), Type("cached"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := bone.GetBone()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	write("($eyes)", modTime)
+	if got := say(); got != "(oo)" {
+		t.Fatalf("want %q, but got %q", "(oo)", got)
+	}
+	t.Run("modified", func(t *testing.T) {
+		write("[$eyes]", modTime.Add(time.Second))
+		if got := say(); got != "[oo]" {
+			t.Errorf("want %q, but got %q", "[oo]", got)
+		}
+	})
+	t.Run("resized", func(t *testing.T) {
+		write("{{$eyes}}", modTime.Add(time.Second))
+		if got := say(); got != "{{oo}}" {
+			t.Errorf("want %q, but got %q", "{{oo}}", got)
+		}
+	})
+	t.Run("unchanged", func(t *testing.T) {
+		// The same modification time and size: the cached template is used.
+		write("<<$eyes>>", modTime.Add(time.Second))
+		if got := say(); got != "{{oo}}" {
+			t.Errorf("want %q, but got %q", "{{oo}}", got)
+		}
+	})
+	t.Run("registered", func(t *testing.T) {
+		useTestRegistry(t)
+		for _, art := range []string{"($eyes)", "[$eyes]"} {
+			if err := Register("cached", art); err != nil {
+				t.Fatal(err)
+			}
+			bone, err := New(WithSources(RegisteredSource()), Type("cached"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := bone.GetBone()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := art[:1] + "oo" + art[len(art)-1:]; want != got {
+				t.Errorf("want %q, but got %q", want, got)
+			}
+		}
+	})
+}
+
+func BenchmarkSay(b *testing.B) {
+	const phrase = "The quick brown fox jumps over the lazy dog."
+	bench := func(b *testing.B, opts []Option, reset bool) {
+		bone, err := New(opts...)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if reset {
+				templates.reset()
+			}
+			if _, err := bone.Say(phrase); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	cases := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "binary"},
+		{
+			name: "directory",
+			opts: []Option{
+				WithSources(DirSource(filepath.Join("testdata", "testdir"))),
+				Type("test"),
+			},
+		},
+	}
+	for _, tc := range cases {
+		b.Run(tc.name+"/cached", func(b *testing.B) { bench(b, tc.opts, false) })
+		b.Run(tc.name+"/uncached", func(b *testing.B) { bench(b, tc.opts, true) })
+	}
+}
+
+// wrappedSource is a struct source whose type is comparable, but whose
+// fs.FS may not be.
+type wrappedSource struct {
+	fs.FS
+	name string
+}
+
+func (s wrappedSource) Name() string { return s.name }
+
+func TestTemplateCache_UncomparableSource(t *testing.T) {
+	source := wrappedSource{
+		FS: fstest.MapFS{
+			"wrapped.bone": {Data: []byte("$the_bone = <<EOB;\n($eyes)\nEOB\n")},
+		},
+		name: "wrapped",
+	}
+	for i := 0; i < 2; i++ {
+		bone, err := New(WithSources(source), Type("wrapped"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := bone.GetBone()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "(oo)"; want != got {
+			t.Errorf("want %q, but got %q", want, got)
+		}
+	}
+}
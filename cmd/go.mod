@@ -24,3 +24,8 @@ require (
 	golang.org/x/term v0.0.0-20201210144234-2321bbc49cbf // indirect
 	golang.org/x/text v0.3.6 // indirect
 )
+
+// The CLI is built against the library in this tree during development.
+// Drop this replace and require the tagged library before releasing, since
+// go install refuses modules with replace directives. See make check/release.
+replace github.com/anthonycuervo23/bonesay/v2 => ../
@@ -12,9 +12,11 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/Code-Hex/go-wordwrap"
+	"github.com/anthonycuervo23/bonesay/cmd/v2/internal/screen"
 	"github.com/anthonycuervo23/bonesay/cmd/v2/internal/super"
 	bonesay "github.com/anthonycuervo23/bonesay/v2"
 	"github.com/anthonycuervo23/bonesay/v2/decoration"
@@ -23,26 +25,12 @@ import (
 	"github.com/mattn/go-colorable"
 )
 
-func init() {
-	// safely set the seed globally so we generate random ids. Tries to use a
-	// crypto seed before falling back to time.
-	var seed int64
-	cryptoseed, err := cryptorand.Int(cryptorand.Reader, big.NewInt(math.MaxInt64))
-	if err != nil {
-		// This should not happen, but worst-case fallback to time-based seed.
-		seed = time.Now().UnixNano()
-	} else {
-		seed = cryptoseed.Int64()
-	}
-	rand.Seed(seed)
-}
-
 // options struct for parse command line arguments
 type options struct {
 	Help     bool   `short:"h"`
 	Eyes     string `short:"e"`
 	Tongue   string `short:"T"`
-	Width    int    `short:"W"`
+	Width    string `short:"W"`
 	Borg     bool   `short:"b"`
 	Dead     bool   `short:"d"`
 	Greedy   bool   `short:"g"`
@@ -52,6 +40,8 @@ type options struct {
 	Wired    bool   `short:"w"`
 	Youthful bool   `short:"y"`
 	List     bool   `short:"l"`
+	Mood     string `long:"mood"`
+	Moods    bool   `long:"list-moods"`
 	NewLine  bool   `short:"n"`
 	File     string `short:"f"`
 	Bold     bool   `long:"bold"`
@@ -59,6 +49,55 @@ type options struct {
 	Random   bool   `long:"random"`
 	Rainbow  bool   `long:"rainbow"`
 	Aurora   bool   `long:"aurora"`
+	Seed     *int64 `long:"seed"`
+
+	BalloonStyle    string   `long:"balloon-style"`
+	BalloonPosition string   `long:"balloon-position"`
+	Align           string   `long:"align"`
+	PaddingX        uint     `long:"padding-x" default:"1"`
+	PaddingY        uint     `long:"padding-y"`
+	MinWidth        uint     `long:"min-width"`
+	TabWidth        uint     `long:"tab-width" default:"8"`
+	Sanitize        string   `long:"sanitize"`
+	Wrap            string   `long:"wrap"`
+	Markdown        bool     `long:"markdown"`
+	Table           string   `long:"table" optional:"yes" optional-value:"auto"`
+	Vars            []string `long:"var"`
+	Dialog          string   `long:"dialog"`
+	DialogLayout    string   `long:"dialog-layout"`
+}
+
+var alignments = map[string]bonesay.Alignment{
+	"left":    bonesay.AlignLeft,
+	"center":  bonesay.AlignCenter,
+	"right":   bonesay.AlignRight,
+	"justify": bonesay.AlignJustify,
+}
+
+var balloonPositions = map[string]bonesay.BalloonPosition{
+	"above": bonesay.BalloonAbove,
+	"below": bonesay.BalloonBelow,
+	"left":  bonesay.BalloonLeft,
+	"right": bonesay.BalloonRight,
+}
+
+var sanitizePolicies = map[string]bonesay.SanitizePolicy{
+	"strip":  bonesay.SanitizeStrip,
+	"escape": bonesay.SanitizeEscape,
+	"pass":   bonesay.SanitizePass,
+}
+
+var tableFormats = map[string]bonesay.TableFormat{
+	"auto":    bonesay.TableAuto,
+	"csv":     bonesay.TableCSV,
+	"tsv":     bonesay.TableTSV,
+	"columns": bonesay.TableColumns,
+}
+
+var wrapStrategies = map[string]bonesay.WrapStrategy{
+	"hard":        bonesay.WrapHard,
+	"punctuation": bonesay.WrapPunctuation,
+	"hyphenate":   bonesay.WrapHyphenate,
 }
 
 // CLI prepare for running command-line.
@@ -109,17 +148,33 @@ func (c *CLI) mow(argv []string) error {
 			return err
 		}
 		for _, bonePath := range bonePaths {
-			if bonePath.LocationType == bonesay.InBinary {
+			if bonePath.Err != nil {
+				fmt.Fprintf(c.stderr, "%s: skipped %s: %s\n", c.program(), bonePath.Name, bonePath.Err)
+				continue
+			}
+			switch bonePath.LocationType {
+			case bonesay.InBinary:
 				fmt.Fprintf(c.stdout, "Bone files in binary:\n")
-			} else {
+			case bonesay.InMemory:
+				fmt.Fprintf(c.stdout, "Registered bone files:\n")
+			default:
 				fmt.Fprintf(c.stdout, "Bone files in %s:\n", bonePath.Name)
 			}
-			fmt.Fprintln(c.stdout, wordwrap.WrapString(strings.Join(bonePath.BoneFiles, " "), 80))
+			fmt.Fprintln(c.stdout, wordwrap.WrapString(strings.Join(bonePath.Names(), " "), 80))
 			fmt.Fprintln(c.stdout)
 		}
 		return nil
 	}
 
+	if opts.Moods {
+		tw := tabwriter.NewWriter(c.stdout, 0, 8, 2, ' ', 0)
+		for _, name := range bonesay.Moods() {
+			mood, _ := bonesay.LookupMood(name)
+			fmt.Fprintf(tw, "%s\teyes %q\ttongue %q\n", name, mood.Eyes, mood.Tongue)
+		}
+		return tw.Flush()
+	}
+
 	if err := c.mowmow(&opts, args); err != nil {
 		return err
 	}
@@ -146,14 +201,55 @@ func (c *CLI) usage() []byte {
 	year := strconv.Itoa(time.Now().Year())
 	return []byte(c.program() + ` version ` + c.Version + `, (c) ` + year + ` codehex + anthonycuervo23
 Usage: ` + c.program() + ` [-bdgpstwy] [-h] [-e eyes] [-f bonefile] [--random]
-          [-l] [-n] [-T tongue] [-W wrapcolumn]
-          [--bold] [--rainbow] [--aurora] [--super] [message]
+          [-l] [-n] [-T tongue] [-W wrapcolumn|auto]
+          [--mood mood] [--list-moods]
+          [--bold] [--rainbow] [--aurora] [--super] [--seed seed]
+          [--balloon-style style] [--balloon-position above|below|left|right]
+          [--align left|center|right|justify]
+          [--padding-x columns] [--padding-y lines] [--min-width columns]
+          [--tab-width columns] [--sanitize strip|escape|pass]
+          [--wrap hard|punctuation|hyphenate] [--markdown]
+          [--table[=auto|csv|tsv|columns]] [--var name=value]
+          [--dialog script] [--dialog-layout turns|side-by-side] [message]
+
+Balloon styles: ` + strings.Join(bonesay.BalloonStyles(), ", ") + `
+Moods: ` + strings.Join(bonesay.Moods(), ", ") + `
 
 Original Author: (c) 1999 Tony Monroe
 `)
 }
 
-func (c *CLI) generateOptions(opts *options) []bonesay.Option {
+// newRand returns the random generator for --random and --aurora.
+// It is seeded by --seed if specified, so that the output is reproducible.
+func newRand(opts *options) *rand.Rand {
+	if opts.Seed != nil {
+		return rand.New(rand.NewSource(*opts.Seed))
+	}
+	// Tries to use a crypto seed before falling back to time.
+	var seed int64
+	cryptoseed, err := cryptorand.Int(cryptorand.Reader, big.NewInt(math.MaxInt64))
+	if err != nil {
+		// This should not happen, but worst-case fallback to time-based seed.
+		seed = time.Now().UnixNano()
+	} else {
+		seed = cryptoseed.Int64()
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
+// terminalWidth returns the width of the terminal for -W auto.
+// If the stdout is not a terminal, $COLUMNS or 80 is used.
+func terminalWidth() int {
+	if width := screen.Width(); width > 0 {
+		return width
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return 80
+}
+
+func (c *CLI) generateOptions(opts *options, r *rand.Rand) ([]bonesay.Option, error) {
 	o := make([]bonesay.Option, 0, 8)
 	if opts.File == "-" {
 		bones := boneList()
@@ -170,7 +266,7 @@ func (c *CLI) generateOptions(opts *options) []bonesay.Option {
 		)
 	}
 	if opts.Random {
-		o = append(o, bonesay.Random())
+		o = append(o, bonesay.RandomWithSource(r))
 	}
 	if opts.Eyes != "" {
 		o = append(o, bonesay.Eyes(opts.Eyes))
@@ -178,13 +274,93 @@ func (c *CLI) generateOptions(opts *options) []bonesay.Option {
 	if opts.Tongue != "" {
 		o = append(o, bonesay.Tongue(opts.Tongue))
 	}
-	if opts.Width > 0 {
-		o = append(o, bonesay.BallonWidth(uint(opts.Width)))
+	if len(opts.Vars) > 0 {
+		vars := make(map[string]string, len(opts.Vars))
+		for _, v := range opts.Vars {
+			name, value, ok := cut(v, "=")
+			if !ok || name == "" {
+				return nil, fmt.Errorf("invalid variable %q, it must be name=value", v)
+			}
+			vars[name] = value
+		}
+		o = append(o, bonesay.Vars(vars))
+	}
+	switch opts.Width {
+	case "":
+	case "auto":
+		o = append(o, bonesay.FitWidth(terminalWidth()))
+	default:
+		width, err := strconv.Atoi(opts.Width)
+		if err != nil {
+			return nil, fmt.Errorf("invalid wrap column %q, it must be a number or auto", opts.Width)
+		}
+		if width > 0 {
+			o = append(o, bonesay.BallonWidth(uint(width)))
+		}
 	}
 	if opts.NewLine {
 		o = append(o, bonesay.DisableWordWrap())
 	}
-	return selectFace(opts, o)
+	if opts.Markdown {
+		o = append(o, bonesay.Markdown())
+	}
+	if opts.Table != "" {
+		format, ok := tableFormats[opts.Table]
+		if !ok {
+			return nil, fmt.Errorf("unknown table format %q, available formats are auto, csv, tsv, columns", opts.Table)
+		}
+		o = append(o, bonesay.Table(format))
+	}
+	if opts.BalloonStyle != "" {
+		style, ok := bonesay.LookupBalloonStyle(opts.BalloonStyle)
+		if !ok {
+			return nil, fmt.Errorf("unknown balloon style %q, available styles are %s",
+				opts.BalloonStyle, strings.Join(bonesay.BalloonStyles(), ", "))
+		}
+		o = append(o, bonesay.WithBalloonStyle(style))
+	}
+	if opts.BalloonPosition != "" {
+		position, ok := balloonPositions[opts.BalloonPosition]
+		if !ok {
+			return nil, fmt.Errorf("unknown balloon position %q, available positions are above, below, left, right", opts.BalloonPosition)
+		}
+		o = append(o, bonesay.WithBalloonPosition(position))
+	}
+	if opts.Align != "" {
+		align, ok := alignments[opts.Align]
+		if !ok {
+			return nil, fmt.Errorf("unknown alignment %q, available alignments are left, center, right, justify", opts.Align)
+		}
+		o = append(o, bonesay.Align(align))
+	}
+	if opts.Sanitize != "" {
+		policy, ok := sanitizePolicies[opts.Sanitize]
+		if !ok {
+			return nil, fmt.Errorf("unknown sanitize policy %q, available policies are strip, escape, pass", opts.Sanitize)
+		}
+		o = append(o, bonesay.Sanitize(policy))
+	}
+	if opts.Wrap != "" {
+		strategy, ok := wrapStrategies[opts.Wrap]
+		if !ok {
+			return nil, fmt.Errorf("unknown wrap strategy %q, available strategies are hard, punctuation, hyphenate", opts.Wrap)
+		}
+		o = append(o, bonesay.WithWrapStrategy(strategy))
+	}
+	o = append(o,
+		bonesay.Padding(opts.PaddingX, opts.PaddingY),
+		bonesay.MinBalloonWidth(opts.MinWidth),
+		bonesay.TabWidth(opts.TabWidth),
+	)
+	// The mood overrides -e and -T.
+	if name := moodName(opts); name != "" {
+		mood, err := lookupMood(name)
+		if err != nil {
+			return nil, err
+		}
+		o = append(o, bonesay.WithMood(mood))
+	}
+	return o, nil
 }
 
 func boneList() []string {
@@ -194,7 +370,7 @@ func boneList() []string {
 	}
 	list := make([]string, 0)
 	for _, bone := range bones {
-		list = append(list, bone.BoneFiles...)
+		list = append(list, bone.Names()...)
 	}
 	return list
 }
@@ -212,13 +388,26 @@ func (c *CLI) phrase(opts *options, args []string) string {
 }
 
 func (c *CLI) mowmow(opts *options, args []string) error {
-	phrase := c.phrase(opts, args)
-	o := c.generateOptions(opts)
-	if opts.Super {
-		return super.RunSuperBone(phrase, opts.Bold, o...)
+	// The dialog script is read instead of the phrase.
+	var phrase string
+	if opts.Dialog == "" {
+		phrase = c.phrase(opts, args)
+	}
+	r := newRand(opts)
+	o, err := c.generateOptions(opts, r)
+	if err != nil {
+		return err
 	}
 
-	say, err := bonesay.Say(phrase, o...)
+	var say string
+	switch {
+	case opts.Dialog != "":
+		say, err = c.dialog(opts, o)
+	case opts.Super:
+		return super.RunSuperBone(phrase, opts.Bold, o...)
+	default:
+		say, err = bonesay.Say(phrase, o...)
+	}
 	if err != nil {
 		var notfound *bonesay.NotFound
 		if errors.As(err, &notfound) {
@@ -236,7 +425,7 @@ func (c *CLI) mowmow(opts *options, args []string) error {
 		options = append(options, decoration.WithRainbow())
 	}
 	if opts.Aurora {
-		options = append(options, decoration.WithAurora(rand.Intn(256)))
+		options = append(options, decoration.WithAurora(r.Intn(256)))
 	}
 
 	w := decoration.NewWriter(c.stdout, options...)
@@ -245,48 +434,36 @@ func (c *CLI) mowmow(opts *options, args []string) error {
 	return nil
 }
 
-func selectFace(opts *options, o []bonesay.Option) []bonesay.Option {
+// moodName returns the name of the mood which is selected by the flags.
+// The flags of the original cowsay take precedence over --mood.
+func moodName(opts *options) string {
 	switch {
 	case opts.Borg:
-		o = append(o,
-			bonesay.Eyes("=="),
-			bonesay.Tongue("  "),
-		)
+		return "borg"
 	case opts.Dead:
-		o = append(o,
-			bonesay.Eyes("xx"),
-			bonesay.Tongue("U "),
-		)
+		return "dead"
 	case opts.Greedy:
-		o = append(o,
-			bonesay.Eyes("$$"),
-			bonesay.Tongue("  "),
-		)
+		return "greedy"
 	case opts.Paranoia:
-		o = append(o,
-			bonesay.Eyes("@@"),
-			bonesay.Tongue("  "),
-		)
+		return "paranoid"
 	case opts.Stoned:
-		o = append(o,
-			bonesay.Eyes("**"),
-			bonesay.Tongue("U "),
-		)
+		return "stoned"
 	case opts.Tired:
-		o = append(o,
-			bonesay.Eyes("--"),
-			bonesay.Tongue("  "),
-		)
+		return "tired"
 	case opts.Wired:
-		o = append(o,
-			bonesay.Eyes("OO"),
-			bonesay.Tongue("  "),
-		)
+		return "wired"
 	case opts.Youthful:
-		o = append(o,
-			bonesay.Eyes(".."),
-			bonesay.Tongue("  "),
-		)
+		return "youthful"
+	}
+	return opts.Mood
+}
+
+// lookupMood returns the mood by name.
+func lookupMood(name string) (bonesay.Mood, error) {
+	mood, ok := bonesay.LookupMood(name)
+	if !ok {
+		return bonesay.Mood{}, fmt.Errorf("unknown mood %q, available moods are %s",
+			name, strings.Join(bonesay.Moods(), ", "))
 	}
-	return o
+	return mood, nil
 }
@@ -10,6 +10,14 @@ import (
 )
 
 func TestCLI_Run(t *testing.T) {
+	t.Setenv("BONEPATH", filepath.Join("..", "..", "testdata", "cows"))
+	// The bonefiles in the data directories must not change the random bone.
+	empty := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", empty)
+	t.Setenv("XDG_DATA_DIRS", empty)
+	// -W auto uses $COLUMNS since the stdout is not a terminal in tests.
+	t.Setenv("COLUMNS", "40")
+
 	clis := []struct {
 		name     string
 		thinking bool
@@ -63,6 +71,12 @@ func TestCLI_Run(t *testing.T) {
 					argv:     []string{"-d"},
 					testfile: "d_option.txt",
 				},
+				{
+					name:     "dead mood",
+					phrase:   "0xdeadbeef",
+					argv:     []string{"--mood", "dead"},
+					testfile: "d_option.txt",
+				},
 				{
 					name:     "greedy mode",
 					phrase:   "give me money",
@@ -111,6 +125,78 @@ func TestCLI_Run(t *testing.T) {
 					argv:     []string{"-f", "tux"},
 					testfile: "f_tux_option.txt",
 				},
+				{
+					name:     "random with seed",
+					phrase:   "same bone every time",
+					argv:     []string{"--random", "--seed", "42"},
+					testfile: "random_seed_option.txt",
+				},
+				{
+					name:     "aurora with seed",
+					phrase:   "same colors",
+					argv:     []string{"--aurora", "--seed", "42"},
+					testfile: "aurora_seed_option.txt",
+				},
+				{
+					name:     "rounded balloon style",
+					phrase:   "round and round",
+					argv:     []string{"--balloon-style", "rounded"},
+					testfile: "balloon_style_rounded_option.txt",
+				},
+				{
+					name:     "centered with padding",
+					phrase:   "release v2.0.0\nis out now",
+					argv:     []string{"--align", "center", "--padding-x", "2", "--padding-y", "1", "--min-width", "20"},
+					testfile: "align_padding_option.txt",
+				},
+				{
+					name:     "control characters are escaped",
+					phrase:   "name\tbone\r\nbell\a\x1b[2J",
+					argv:     []string{"--sanitize", "escape", "--tab-width", "4"},
+					testfile: "sanitize_escape_option.txt",
+				},
+				{
+					name:     "long words are hyphenated",
+					phrase:   "see https://example.com/bonesay for internationalization",
+					argv:     []string{"-W", "12", "--wrap", "hyphenate"},
+					testfile: "wrap_hyphenate_option.txt",
+				},
+				{
+					name:     "auto width",
+					phrase:   "the balloon is as wide as the terminal, so the whole picture fits in it",
+					argv:     []string{"-f", "tux", "-W", "auto"},
+					testfile: "W_auto_option.txt",
+				},
+				{
+					name:     "markdown",
+					phrase:   "**v2.1.0** is out\n---\n- `--wrap` breaks *long* words\n- `-W auto` fits the terminal",
+					argv:     []string{"-f", "tux", "--markdown"},
+					testfile: "markdown_option.txt",
+				},
+				{
+					name:     "table",
+					phrase:   "NAME            READY   STATUS    AGE\nweb-7d4b9c8f6   1/1     Running   5d\ndb-0            0/1     Pending   12d\n",
+					argv:     []string{"-f", "tux", "-W", "40", "--table"},
+					testfile: "table_option.txt",
+				},
+				{
+					name:     "balloon on the right",
+					phrase:   "penguins prefer the right side",
+					argv:     []string{"-f", "tux", "--balloon-position", "right"},
+					testfile: "balloon_position_right_option.txt",
+				},
+				{
+					name:     "dialog",
+					phrase:   "# stand-up\ntux: ship it?\ntux: shipped!\n  it is in the next release",
+					argv:     []string{"--dialog", "-"},
+					testfile: "dialog_option.txt",
+				},
+				{
+					name:     "dialog with wrap column",
+					phrase:   "tux: the balloon is wrapped at the column\ntux: ok",
+					argv:     []string{"--dialog", "-", "-W", "10"},
+					testfile: "dialog_W_option.txt",
+				},
 			}
 			for _, tt := range tests {
 				tt := tt
@@ -160,6 +246,235 @@ func TestCLI_Run(t *testing.T) {
 					t.Errorf("want %q, but got %q", want, stderr.String())
 				}
 			})
+
+			t.Run("unknown alignment", func(t *testing.T) {
+				var stderr bytes.Buffer
+				c := &CLI{
+					Thinking: cli.thinking,
+					stderr:   &stderr,
+				}
+
+				exit := c.Run([]string{"--align", "middle", "hello"})
+				if exit == 0 {
+					t.Errorf("unexpected exit code: %d", exit)
+				}
+				want := fmt.Sprintf("%s: unknown alignment \"middle\"", cli.name)
+				if !strings.HasPrefix(stderr.String(), want) {
+					t.Errorf("want %q, but got %q", want, stderr.String())
+				}
+			})
+
+			t.Run("unknown balloon style", func(t *testing.T) {
+				var stderr bytes.Buffer
+				c := &CLI{
+					Thinking: cli.thinking,
+					stderr:   &stderr,
+				}
+
+				exit := c.Run([]string{"--balloon-style", "unknown", "hello"})
+				if exit == 0 {
+					t.Errorf("unexpected exit code: %d", exit)
+				}
+				want := fmt.Sprintf("%s: unknown balloon style \"unknown\"", cli.name)
+				if !strings.HasPrefix(stderr.String(), want) {
+					t.Errorf("want %q, but got %q", want, stderr.String())
+				}
+			})
+
+			t.Run("invalid wrap column", func(t *testing.T) {
+				var stderr bytes.Buffer
+				c := &CLI{
+					Thinking: cli.thinking,
+					stderr:   &stderr,
+				}
+
+				exit := c.Run([]string{"-W", "wide", "hello"})
+				if exit == 0 {
+					t.Errorf("unexpected exit code: %d", exit)
+				}
+				want := fmt.Sprintf("%s: invalid wrap column \"wide\"", cli.name)
+				if !strings.HasPrefix(stderr.String(), want) {
+					t.Errorf("want %q, but got %q", want, stderr.String())
+				}
+			})
+
+			t.Run("unknown balloon position", func(t *testing.T) {
+				var stderr bytes.Buffer
+				c := &CLI{
+					Thinking: cli.thinking,
+					stderr:   &stderr,
+				}
+
+				exit := c.Run([]string{"--balloon-position", "top", "hello"})
+				if exit == 0 {
+					t.Errorf("unexpected exit code: %d", exit)
+				}
+				want := fmt.Sprintf("%s: unknown balloon position \"top\"", cli.name)
+				if !strings.HasPrefix(stderr.String(), want) {
+					t.Errorf("want %q, but got %q", want, stderr.String())
+				}
+			})
+
+			t.Run("unknown table format", func(t *testing.T) {
+				var stderr bytes.Buffer
+				c := &CLI{
+					Thinking: cli.thinking,
+					stderr:   &stderr,
+				}
+
+				exit := c.Run([]string{"--table=json", "hello"})
+				if exit == 0 {
+					t.Errorf("unexpected exit code: %d", exit)
+				}
+				want := fmt.Sprintf("%s: unknown table format \"json\"", cli.name)
+				if !strings.HasPrefix(stderr.String(), want) {
+					t.Errorf("want %q, but got %q", want, stderr.String())
+				}
+			})
+
+			t.Run("unknown wrap strategy", func(t *testing.T) {
+				var stderr bytes.Buffer
+				c := &CLI{
+					Thinking: cli.thinking,
+					stderr:   &stderr,
+				}
+
+				exit := c.Run([]string{"--wrap", "soft", "hello"})
+				if exit == 0 {
+					t.Errorf("unexpected exit code: %d", exit)
+				}
+				want := fmt.Sprintf("%s: unknown wrap strategy \"soft\"", cli.name)
+				if !strings.HasPrefix(stderr.String(), want) {
+					t.Errorf("want %q, but got %q", want, stderr.String())
+				}
+			})
+
+			t.Run("eyes do not fit", func(t *testing.T) {
+				var stderr bytes.Buffer
+				c := &CLI{
+					Thinking: cli.thinking,
+					stderr:   &stderr,
+				}
+
+				exit := c.Run([]string{"-f", "default", "-e", "o目", "hello"})
+				if exit == 0 {
+					t.Errorf("unexpected exit code: %d", exit)
+				}
+				want := fmt.Sprintf("%s: eyes \"o目\" do not fit", cli.name)
+				if !strings.HasPrefix(stderr.String(), want) {
+					t.Errorf("want %q, but got %q", want, stderr.String())
+				}
+			})
+
+			t.Run("list moods", func(t *testing.T) {
+				var stdout bytes.Buffer
+				c := &CLI{
+					Thinking: cli.thinking,
+					stdout:   &stdout,
+				}
+
+				exit := c.Run([]string{"--list-moods"})
+				if exit != 0 {
+					t.Fatalf("unexpected exit code: %d", exit)
+				}
+				want := "borg      eyes \"==\"  tongue \"  \"\n"
+				if !strings.HasPrefix(stdout.String(), want) {
+					t.Errorf("want %q, but got %q", want, stdout.String())
+				}
+			})
+
+			t.Run("unknown mood", func(t *testing.T) {
+				var stderr bytes.Buffer
+				c := &CLI{
+					Thinking: cli.thinking,
+					stderr:   &stderr,
+				}
+
+				exit := c.Run([]string{"--mood", "happy", "hello"})
+				if exit == 0 {
+					t.Errorf("unexpected exit code: %d", exit)
+				}
+				want := fmt.Sprintf("%s: unknown mood \"happy\"", cli.name)
+				if !strings.HasPrefix(stderr.String(), want) {
+					t.Errorf("want %q, but got %q", want, stderr.String())
+				}
+			})
+
+			t.Run("unknown dialog layout", func(t *testing.T) {
+				var stderr bytes.Buffer
+				c := &CLI{
+					Thinking: cli.thinking,
+					stderr:   &stderr,
+					stdin:    strings.NewReader("tux: hello"),
+				}
+
+				exit := c.Run([]string{"--dialog", "-", "--dialog-layout", "grid"})
+				if exit == 0 {
+					t.Errorf("unexpected exit code: %d", exit)
+				}
+				want := fmt.Sprintf("%s: unknown dialog layout \"grid\"", cli.name)
+				if !strings.HasPrefix(stderr.String(), want) {
+					t.Errorf("want %q, but got %q", want, stderr.String())
+				}
+			})
+
+			t.Run("invalid dialog script", func(t *testing.T) {
+				var stderr bytes.Buffer
+				c := &CLI{
+					Thinking: cli.thinking,
+					stderr:   &stderr,
+					stdin:    strings.NewReader("tux: hello\ntux color=red: hi"),
+				}
+
+				exit := c.Run([]string{"--dialog", "-"})
+				if exit == 0 {
+					t.Errorf("unexpected exit code: %d", exit)
+				}
+				want := fmt.Sprintf("%s: -:2: unknown attribute \"color=red\"", cli.name)
+				if !strings.HasPrefix(stderr.String(), want) {
+					t.Errorf("want %q, but got %q", want, stderr.String())
+				}
+			})
 		})
 	}
 }
+
+func TestCLI_Run_Vars(t *testing.T) {
+	t.Setenv("BONEPATH", filepath.Join("..", "..", "testdata", "vars"))
+
+	t.Run("var option", func(t *testing.T) {
+		var stdout bytes.Buffer
+		c := &CLI{
+			stdout: &stdout,
+			stdin:  strings.NewReader("my badge is too short for my name"),
+		}
+		exit := c.Run([]string{"-f", "badge", "--var", "name=penguin"})
+		if exit != 0 {
+			t.Fatalf("unexpected exit code: %d", exit)
+		}
+		content, err := ioutil.ReadFile(filepath.Join("..", "..", "testdata", "bonesay", "var_option.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := string(content)
+		if got := stdout.String(); want != got {
+			t.Errorf("want\n%s\n-----got\n%s\n", want, got)
+		}
+	})
+
+	t.Run("invalid variable", func(t *testing.T) {
+		var stderr bytes.Buffer
+		c := &CLI{
+			stderr: &stderr,
+		}
+
+		exit := c.Run([]string{"-f", "badge", "--var", "name", "hello"})
+		if exit == 0 {
+			t.Errorf("unexpected exit code: %d", exit)
+		}
+		want := "bonesay: invalid variable \"name\""
+		if !strings.HasPrefix(stderr.String(), want) {
+			t.Errorf("want %q, but got %q", want, stderr.String())
+		}
+	})
+}
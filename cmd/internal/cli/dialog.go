@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	bonesay "github.com/anthonycuervo23/bonesay/v2"
+)
+
+var dialogLayouts = map[string]bonesay.SceneLayout{
+	"turns":        bonesay.SceneTurns,
+	"side-by-side": bonesay.SceneSideBySide,
+}
+
+// dialogLine is a turn in the dialog script.
+type dialogLine struct {
+	bonefile string
+	eyes     string
+	tongue   string
+	mood     string
+	phrase   string
+}
+
+// parseDialog parses the dialog script. Each turn is written as
+//
+//	bonefile [mood=name] [eyes=xx] [tongue=xx]: phrase
+//
+// The lines which begin with a space continue the phrase of the previous
+// turn. Blank lines and the lines which begin with "#" are ignored.
+func parseDialog(name string, r io.Reader) ([]*dialogLine, error) {
+	var lines []*dialogLine
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		text := scanner.Text()
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if text[0] == ' ' || text[0] == '\t' {
+			if len(lines) == 0 {
+				return nil, fmt.Errorf("%s:%d: continuation line without a speaker", name, n)
+			}
+			last := lines[len(lines)-1]
+			last.phrase += "\n" + trimmed
+			continue
+		}
+		i := strings.Index(text, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("%s:%d: missing \":\" after the speaker", name, n)
+		}
+		fields := strings.Fields(text[:i])
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("%s:%d: missing bonefile of the speaker", name, n)
+		}
+		line := &dialogLine{
+			bonefile: fields[0],
+			phrase:   strings.TrimSpace(text[i+1:]),
+		}
+		for _, field := range fields[1:] {
+			key, value, ok := cut(field, "=")
+			switch {
+			case ok && key == "eyes":
+				line.eyes = value
+			case ok && key == "tongue":
+				line.tongue = value
+			case ok && key == "mood":
+				line.mood = value
+			default:
+				return nil, fmt.Errorf("%s:%d: unknown attribute %q, available attributes are mood, eyes, tongue", name, n, field)
+			}
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// cut is strings.Cut which is not available in go1.17.
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// dialogWidth returns the width of the scene by -W. The scene fits in the
+// terminal unless the balloons are wrapped at the column of -W, which
+// would be overridden by fitting them.
+func dialogWidth(width string) int {
+	if n, err := strconv.Atoi(width); err == nil && n > 0 {
+		return 0
+	}
+	return terminalWidth()
+}
+
+// dialog renders the dialog script of --dialog as a scene. Each speaker
+// is rendered with o and its own bonefile, mood, eyes and tongue.
+func (c *CLI) dialog(opts *options, o []bonesay.Option) (string, error) {
+	layout := bonesay.SceneTurns
+	if opts.DialogLayout != "" {
+		l, ok := dialogLayouts[opts.DialogLayout]
+		if !ok {
+			return "", fmt.Errorf("unknown dialog layout %q, available layouts are turns, side-by-side", opts.DialogLayout)
+		}
+		layout = l
+	}
+
+	r := c.stdin
+	if opts.Dialog != "-" {
+		f, err := os.Open(opts.Dialog)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		r = f
+	}
+	lines, err := parseDialog(opts.Dialog, r)
+	if err != nil {
+		return "", err
+	}
+
+	scene := bonesay.NewScene(layout, dialogWidth(opts.Width))
+	for _, line := range lines {
+		bo := append(o[:len(o):len(o)], bonesay.Type(line.bonefile))
+		if line.mood != "" {
+			mood, err := lookupMood(line.mood)
+			if err != nil {
+				return "", err
+			}
+			bo = append(bo, bonesay.WithMood(mood))
+		}
+		if line.eyes != "" {
+			bo = append(bo, bonesay.Eyes(line.eyes))
+		}
+		if line.tongue != "" {
+			bo = append(bo, bonesay.Tongue(line.tongue))
+		}
+		bone, err := bonesay.New(bo...)
+		if err != nil {
+			return "", err
+		}
+		scene.Add(bone, line.phrase)
+	}
+	return scene.Say()
+}
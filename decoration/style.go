@@ -0,0 +1,29 @@
+package decoration
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Style is a style of text which is drawn by an escape sequence.
+type Style int
+
+// Styles of text.
+const (
+	Bold    Style = 1
+	Italic  Style = 3
+	Reverse Style = 7
+)
+
+// Sequence returns the escape sequence which resets the style of text and
+// then starts styles. Sequence() only resets the style.
+func Sequence(styles ...Style) string {
+	var b strings.Builder
+	b.WriteString("\x1b[0")
+	for _, s := range styles {
+		b.WriteByte(';')
+		b.WriteString(strconv.Itoa(int(s)))
+	}
+	b.WriteByte('m')
+	return b.String()
+}
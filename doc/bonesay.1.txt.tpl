@@ -7,8 +7,12 @@ Neo bonesay/bonethink - configurable speaking/thinking bone (and a bit more)
 
 SYNOPSIS
 --------
-bonesay [-e _eye_string_] [-f _bonefile_] [-h] [-l] [-n] [-T _tongue_string_] [-W _column_] [-bdgpstwy]
-       [--random] [--bold] [--rainbow] [--aurora] [--super] [_message_]
+bonesay [-e _eye_string_] [-f _bonefile_] [-h] [-l] [-n] [-T _tongue_string_] [-W _column_|auto] [-bdgpstwy]
+       [--mood _mood_] [--list-moods]
+       [--random] [--bold] [--rainbow] [--aurora] [--super] [--seed _seed_]
+       [--balloon-style _style_] [--balloon-position _position_] [--align _alignment_] [--padding-x _columns_] [--padding-y _lines_]
+       [--min-width _columns_] [--tab-width _columns_] [--sanitize _policy_] [--wrap _strategy_]
+       [--markdown] [--table[=_format_]] [--var _name_=_value_] [--dialog _script_] [--dialog-layout _layout_] [_message_]
 
 DESCRIPTION
 -----------
@@ -25,6 +29,9 @@ after all the switches have been processed.
 
 The *-W* specifies roughly (where the message should be wrapped. The default
 is equivalent to *-W 40* i.e. wrap words at or before the 40th column.
+If *-W auto* is specified, the message is wrapped so that the balloon, which is placed
+at the bonefile's *$ballonOffset* or beside the bone by *--balloon-position*, fits in the width of the terminal. If the standard output
+is not a terminal, *COLUMNS* or 80 columns is used instead.
 
 If any command-line arguments are left over after all switches have
 been processed, they become the bone's message. The program will not
@@ -49,9 +56,15 @@ The *-b* option initiates Borg mode
 
 *-y* brings on the bone's youthful appearance.
 
+These modes are also available as moods by *--mood* _mood_, e.g. *--mood borg*, together with the moods which are
+registered by the library. *--list-moods* lists the available moods with their eyes and tongues. The modes above
+take precedence over *--mood*.
+
 The user may specify the *-e* option to select the appearance of the bone's eyes, in which case
-the first two characters of the argument string _eye_string_ will be used. The default eyes are 'oo'. The tongue is similarly
-configurable through *-T* and _tongue_string_; it must be two characters and does not appear by default. However,
+the first two columns of the argument string _eye_string_ will be used. Wide characters such as emoji and CJK take two
+columns, and shorter strings are padded with spaces. It is an error if a wide character is cut at the end of the eyes.
+The default eyes are 'oo'. The tongue is similarly
+configurable through *-T* and _tongue_string_; it is two columns and does not appear by default. However,
 it does appear in the 'dead' and 'stoned' modes. Any configuration
 done by *-e* and *-T* will be lost if one of the provided modes is used.
 
@@ -69,6 +82,46 @@ To list all bonefiles on the current *BONEPATH*, invoke *bonesay* with the *-l*
 
 *--rainbow* and *--aurora* filters with colors an ASCII picture of a bone saying something
 
+*--seed* _seed_ makes *--random* and *--aurora* reproducible: the same _seed_ picks the same bonefile and colors
+
+*--balloon-style* _style_ draws the balloon in _style_: *classic* (default), *single*, *double*, *rounded*, *heavy*,
+*ascii* or *none*
+
+*--balloon-position* _position_ places the balloon *above* (default), *below*, on the *left* or on the *right* of the bone.
+Except for *above*, the tail in the bonefile is removed and a tail pointing to the bone is drawn instead
+
+*--align* _alignment_ aligns the message in the balloon: *left* (default), *center*, *right* or *justify*
+
+*--padding-x* _columns_ and *--padding-y* _lines_ specify the space around the message in the balloon (default: 1 and 0)
+
+*--min-width* _columns_ specifies the minimum width of the message in the balloon
+
+*--tab-width* _columns_ specifies the interval of the tab stops in the message (default: 8)
+
+*--sanitize* _policy_ specifies how the control characters in the message are treated: *strip* (default) removes them,
+*escape* shows them in caret notation such as *^[*, and *pass* passes them through. Colors and hyperlinks are kept by *strip*
+
+*--wrap* _strategy_ specifies how a word longer than the balloon is broken: *hard* (default) breaks it at the width,
+*punctuation* breaks it after '/', '-', '_' or '.', and *hyphenate* also breaks it between letters with a hyphen
+
+*--markdown* formats the message as a small subset of Markdown: bullet and numbered lists, *\*\*bold\*\**,
+*\*italic\**, *`code`* and horizontal rules such as *---*
+
+*--table*[=_format_] lays out the message as a table whose first row is the header: *auto* (default) detects the format,
+*csv* and *tsv* are comma- and tab-separated values, and *columns* is columns aligned by spaces such as the output of
+*kubectl*. The columns are narrowed to fit in the width of *-W*, and the cells are wrapped in them
+
+*--var* _name_=_value_ fills the variable *$name* in the bonefile with _value_. It may be specified more than once.
+See BONEFILE FORMAT for the variables which a bonefile can declare
+
+*--dialog* _script_ renders a conversation of bones from the script file, or the standard input if _script_ is *-*.
+Each line of the script is a turn written as *bonefile [mood=name] [eyes=xx] [tongue=xx]: message*, and the lines which begin with
+a space continue the message of the previous turn. Blank lines and the lines which begin with *#* are ignored.
+The conversation is fitted in the width of the terminal
+
+*--dialog-layout* _layout_ specifies how the turns of *--dialog* are laid out: *turns* (default) stacks them from top
+to bottom like a chat, and *side-by-side* puts them from left to right
+
 *--super* ...enjoy!
 
 If the program is invoked as *bonethink* then the bone will think its message instead of saying it.
@@ -82,16 +135,29 @@ composed of the character(s) in the *$thoughts* variable. Any backslashes must b
 The name of a bonefile should end with *.bone ,* otherwise it is assumed not to be a bonefile. Also, at-signs (``@'')
 must be backslashed because that is what Perl 5 expects.
 
+A bonefile may declare where the balloon is placed by default, e.g. *$balloonPosition = "right";*. The values are the same
+as *--balloon-position*.
+
+A bonefile may also refer to its own variables other than *$eyes*, *$tongue* and *$thoughts*, such as *${hat}*,
+which are filled by *--var*. The default value and the width of a variable may be declared in the header, e.g.
+*$hat = "^^^";* and *$hat_width = 5;*. If the width is declared, the value is padded with spaces or truncated to it,
+so that the art stays aligned. The widths of *$eyes* and *$tongue* are two columns, unless they are declared by
+*$eyes_width* and *$tongue_width* in the same way.
+
 ENVIRONMENT
 -----------
 The BONEPATH environment variable, if present, will be used to search
 for bonefiles.  It contains a colon-separated list of directories,
-much like *PATH or MANPATH*. It should always contain the */usr/local/share/bones*
-directory, or at least a directory with a file called *default.bone* in it.
+much like *PATH or MANPATH*. Both *.bone* files and the original *.cow* files are used.
+
+Bonefiles are searched in the following order, and the first one found is used:
+the directories in *BONEPATH*, *$XDG_DATA_HOME/bonesay/bones*, *bonesay/bones* in each of
+*$XDG_DATA_DIRS*, and then the bonefiles in binary. Directories which cannot be read are
+skipped and reported by *-l*.
 
 FILES
 -----
-*%PREFIX%/share/bones* holds a sample set of bonefiles. If your *BONEPATH* is not explicitly set, it automatically contains this directory.
+*~/.local/share/bonesay/bones* and *%PREFIX%/share/bonesay/bones* are searched for bonefiles if they exist.
 
 BUGS
 ----
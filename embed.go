@@ -2,6 +2,7 @@ package bonesay
 
 import (
 	"embed"
+	"io/fs"
 	"sort"
 	"strings"
 )
@@ -9,6 +10,15 @@ import (
 //go:embed bones/*
 var bonesDir embed.FS
 
+// binaryFS is the bones directory in binary.
+var binaryFS = func() fs.FS {
+	sub, err := fs.Sub(bonesDir, "bones")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}()
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
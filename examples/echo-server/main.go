@@ -25,6 +25,12 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 
+	// Bone is safe for concurrent use, so it is shared by all connections.
+	bone, err := bonesay.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	go func() {
 		for {
 			conn, err := ln.Accept()
@@ -51,8 +57,9 @@ func main() {
 
 				phrase := strings.TrimSpace(buf.String())
 				log.Println(phrase)
-				say, _ := bonesay.Say(phrase)
-				conn.Write([]byte(say))
+				if _, err := bone.Render(ctx, conn, phrase); err != nil {
+					log.Println("error:", err)
+				}
 			}()
 		}
 	}()
@@ -1,14 +1,11 @@
 module github.com/anthonycuervo23/bonesay/v2
 
 require (
-	github.com/Code-Hex/go-wordwrap v1.0.0
 	github.com/google/go-cmp v0.5.6
 	github.com/mattn/go-runewidth v0.0.13
+	github.com/rivo/uniseg v0.2.0
 )
 
-require (
-	github.com/rivo/uniseg v0.2.0 // indirect
-	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
-)
+require golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
 
 go 1.17
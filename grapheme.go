@@ -0,0 +1,63 @@
+package bonesay
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	runewidth "github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
+)
+
+// eachCluster calls fn with each escape sequence and grapheme cluster in s
+// in order. escape reports whether cluster is an escape sequence.
+// "\r\n" is passed as "\r" and "\n".
+func eachCluster(s string, fn func(cluster string, escape bool)) {
+	for len(s) > 0 {
+		if n := escapeLen(s); n > 0 {
+			fn(s[:n], true)
+			s = s[n:]
+			continue
+		}
+		end := len(s)
+		if i := strings.IndexByte(s[1:], esc); i >= 0 {
+			end = i + 1
+		}
+		g := uniseg.NewGraphemes(s[:end])
+		for g.Next() {
+			if cluster := g.Str(); cluster == "\r\n" {
+				fn("\r", false)
+				fn("\n", false)
+			} else {
+				fn(cluster, false)
+			}
+		}
+		s = s[end:]
+	}
+}
+
+// clusterWidth returns the number of cells which the grapheme cluster
+// occupies on the terminal.
+//
+// The width of East Asian ambiguous characters follows
+// runewidth.DefaultCondition, which is configured by the locale.
+func clusterWidth(cluster string) int {
+	r, size := utf8.DecodeRuneInString(cluster)
+	if size == len(cluster) {
+		return runewidth.RuneWidth(r)
+	}
+	switch {
+	case strings.ContainsRune(cluster, '\uFE0F'):
+		// emoji presentation sequence, e.g. "☺️" and "1️⃣"
+		return 2
+	case isRegionalIndicator(r):
+		// flag sequence, e.g. "🇯🇵"
+		return 2
+	}
+	// ZWJ sequences, emoji modifiers and combining marks are as wide as
+	// the first character which has width.
+	return runewidth.StringWidth(cluster)
+}
+
+func isRegionalIndicator(r rune) bool {
+	return '\U0001F1E6' <= r && r <= '\U0001F1FF'
+}
@@ -0,0 +1,112 @@
+package bonesay
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClusterWidth(t *testing.T) {
+	tests := []struct {
+		name    string
+		cluster string
+		want    int
+	}{
+		{name: "ascii", cluster: "a", want: 1},
+		{name: "CJK", cluster: "漢", want: 2},
+		{name: "hiragana", cluster: "あ", want: 2},
+		{name: "combining mark", cluster: "é", want: 1},
+		{name: "emoji", cluster: "🐶", want: 2},
+		{name: "skin tone", cluster: "👍🏽", want: 2},
+		{name: "ZWJ sequence", cluster: "👨‍👩‍👧", want: 2},
+		{name: "flag", cluster: "🇯🇵", want: 2},
+		{name: "emoji presentation", cluster: "☺️", want: 2},
+		{name: "keycap", cluster: "1️⃣", want: 2},
+		{name: "hebrew with points", cluster: "שׁ", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clusterWidth(tt.cluster); got != tt.want {
+				t.Errorf("want %d, but got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBalloon_graphemes(t *testing.T) {
+	tests := []struct {
+		name   string
+		width  uint
+		phrase string
+		want   string
+	}{
+		{
+			name:   "CJK",
+			width:  10,
+			phrase: "日本語の文章を折り返す",
+			want: "" +
+				" ____________ \n" +
+				"/ 日本語の文 \\\n" +
+				"| 章を折り返 |\n" +
+				"\\ す         /\n" +
+				" ------------ \n",
+		},
+		{
+			name:   "ZWJ sequences are not broken",
+			width:  4,
+			phrase: "👨‍👩‍👧👨‍👩‍👧👨‍👩‍👧",
+			want: "" +
+				" ______ \n" +
+				"/ 👨‍👩‍👧👨‍👩‍👧 \\\n" +
+				"\\ 👨‍👩‍👧   /\n" +
+				" ------ \n",
+		},
+		{
+			name:   "flags and skin tones",
+			width:  15,
+			phrase: "🇯🇵 🇫🇷 👍🏽 ok",
+			want: "" +
+				" _____________ \n" +
+				"< 🇯🇵 🇫🇷 👍🏽 ok >\n" +
+				" ------------- \n",
+		},
+		{
+			name:   "combining marks",
+			width:  15,
+			phrase: "café naïve",
+			want: "" +
+				" ____________ \n" +
+				"< café naïve >\n" +
+				" ------------ \n",
+		},
+		{
+			name:   "RTL",
+			width:  6,
+			phrase: "שָׁלוֹם עוֹלָם مرحبا",
+			want: "" +
+				" _______ \n" +
+				"/ שָׁלוֹם  \\\n" +
+				"| עוֹלָם  |\n" +
+				"\\ مرحبا /\n" +
+				" ------- \n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bone, err := New(FromString(" $thoughts"), BallonWidth(tt.width))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := bone.Balloon(tt.phrase)
+			if tt.want != got {
+				t.Errorf("want\n%s\ngot\n%s", tt.want, got)
+			}
+			// Every line must have the same width.
+			lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+			for _, l := range lines[1:] {
+				if visibleWidth(l) != visibleWidth(lines[0]) {
+					t.Errorf("%q is not as wide as %q", l, lines[0])
+				}
+			}
+		})
+	}
+}
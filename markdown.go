@@ -0,0 +1,173 @@
+package bonesay
+
+import (
+	"strings"
+
+	"github.com/anthonycuervo23/bonesay/v2/decoration"
+)
+
+// Markdown enables a small subset of Markdown in the phrase.
+//
+//   - "- ", "* " or "+ " starts a bullet list item, and "1. " or "1) "
+//     starts a numbered one. The item is wrapped with a hanging indent.
+//   - **bold** and *italic* are drawn by escape sequences.
+//   - `code` is drawn in reverse video. The marks in it are kept as they are.
+//   - A line of three or more "-", "*" or "_" is a horizontal rule which
+//     spans the balloon.
+//
+// A backslash before a mark keeps the mark as it is. The styles are reset
+// at the end of each emphasis, including the colors in the phrase.
+func Markdown() Option {
+	return func(c *Bone) error {
+		c.markdown = true
+		return nil
+	}
+}
+
+// markdownLines splits the paragraph into the lines in the balloon as
+// canonicalizePhrase, and formats it as Markdown.
+func (bone *Bone) markdownLines(paragraph string, width int) []string {
+	paragraph = expandTabs(paragraph, bone.tabWidth)
+	n := listMarker(paragraph)
+	marker, text := paragraph[:n], emphasize(paragraph[n:])
+	if !bone.disableWordWrap {
+		// The marker consists of ASCII characters, so n is its width.
+		width -= n
+		if width < 1 {
+			width = 1
+		}
+		text = wrapString(text, width, bone.wrapStrategy)
+	}
+	lines := strings.Split(text, "\n")
+	lines[0] = marker + lines[0]
+	if len(lines) > 1 {
+		indent := strings.Repeat(" ", n)
+		for i := 1; i < len(lines); i++ {
+			lines[i] = indent + lines[i]
+		}
+	}
+	return lines
+}
+
+// isRule reports whether s is a horizontal rule, which is three or more
+// "-", "*" or "_" which may be separated by spaces.
+func isRule(s string) bool {
+	var mark rune
+	n := 0
+	for _, r := range s {
+		switch {
+		case r == ' ' || r == '\t':
+			continue
+		case mark == 0 && strings.ContainsRune("-*_", r):
+			mark = r
+		case r != mark:
+			return false
+		}
+		n++
+	}
+	return n >= 3
+}
+
+// listMarker returns the length of the list marker at the start of s,
+// including the indentation and the spaces after it. It returns 0 if s is
+// not a list item.
+func listMarker(s string) int {
+	i := 0
+	for i < len(s) && s[i] == ' ' {
+		i++
+	}
+	if i < len(s) && strings.IndexByte("-*+", s[i]) >= 0 {
+		i++
+	} else {
+		start := i
+		for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+			i++
+		}
+		if i == start || i == len(s) || (s[i] != '.' && s[i] != ')') {
+			return 0
+		}
+		i++
+	}
+	if i == len(s) || s[i] != ' ' {
+		return 0
+	}
+	for i < len(s) && s[i] == ' ' {
+		i++
+	}
+	return i
+}
+
+// emphasize replaces the emphasis and the code spans in s with the escape
+// sequences. The marks which are not closed are kept as they are.
+func emphasize(s string) string {
+	var (
+		b            strings.Builder
+		bold, italic bool
+	)
+	style := func(styles ...decoration.Style) string {
+		if bold {
+			styles = append(styles, decoration.Bold)
+		}
+		if italic {
+			styles = append(styles, decoration.Italic)
+		}
+		return decoration.Sequence(styles...)
+	}
+	b.Grow(len(s))
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("\\`*_-+.", s[i+1]) >= 0:
+			b.WriteByte(s[i+1])
+			i += 2
+			continue
+		case c == '`':
+			if j := strings.IndexByte(s[i+1:], '`'); j > 0 {
+				b.WriteString(style(decoration.Reverse))
+				b.WriteString(s[i+1 : i+1+j])
+				b.WriteString(style())
+				i += j + 2
+				continue
+			}
+		case c == '*':
+			mark, open := "*", &italic
+			if strings.HasPrefix(s[i:], "**") {
+				mark, open = "**", &bold
+			}
+			n := len(mark)
+			switch {
+			case *open && s[i-1] != ' ':
+				*open = false
+			case !*open && i+n < len(s) && s[i+n] != ' ' && closes(s[i+n:], mark):
+				*open = true
+			default:
+				b.WriteString(mark)
+				i += n
+				continue
+			}
+			b.WriteString(style())
+			i += n
+			continue
+		}
+		b.WriteByte(c)
+		i++
+	}
+	if bold || italic {
+		b.WriteString(decoration.Sequence())
+	}
+	return b.String()
+}
+
+// closes reports whether mark which closes the emphasis is in s. The mark
+// must follow a character other than a space, and must not be escaped.
+func closes(s, mark string) bool {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case i > 0 && strings.HasPrefix(s[i:], mark) && s[i-1] != ' ':
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package bonesay
+
+import "testing"
+
+func TestEmphasize(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{
+			name: "bold",
+			s:    "a **b** c",
+			want: "a \x1b[0;1mb\x1b[0m c",
+		},
+		{
+			name: "italic",
+			s:    "a *b* c",
+			want: "a \x1b[0;3mb\x1b[0m c",
+		},
+		{
+			name: "nested",
+			s:    "**a *b* c**",
+			want: "\x1b[0;1ma \x1b[0;1;3mb\x1b[0;1m c\x1b[0m",
+		},
+		{
+			name: "code",
+			s:    "run `**go**` now",
+			want: "run \x1b[0;7m**go**\x1b[0m now",
+		},
+		{
+			name: "not closed",
+			s:    "2 * 3 * 4, a*b and `c",
+			want: "2 * 3 * 4, a*b and `c",
+		},
+		{
+			name: "escaped",
+			s:    `\*a\* and *b\*`,
+			want: "*a* and *b*",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := emphasize(tt.s); tt.want != got {
+				t.Errorf("want %q, but got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestListMarker(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{s: "- item", want: 2},
+		{s: "  *   item", want: 6},
+		{s: "+ item", want: 2},
+		{s: "12. item", want: 4},
+		{s: "1) item", want: 3},
+		{s: "-item", want: 0},
+		{s: "**bold**", want: 0},
+		{s: "1.5 items", want: 0},
+		{s: "item", want: 0},
+	}
+	for _, tt := range tests {
+		if got := listMarker(tt.s); tt.want != got {
+			t.Errorf("listMarker(%q): want %d, but got %d", tt.s, tt.want, got)
+		}
+	}
+}
+
+func TestIsRule(t *testing.T) {
+	for s, want := range map[string]bool{
+		"---":     true,
+		"* * *":   true,
+		" _____ ": true,
+		"--":      false,
+		"-*-":     false,
+		"- item":  false,
+		"":        false,
+	} {
+		if got := isRule(s); want != got {
+			t.Errorf("isRule(%q): want %v, but got %v", s, want, got)
+		}
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	const phrase = "Notes\n---\n- long words wrap under the item\n1. numbered"
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "classic",
+			opts: []Option{},
+			want: "" +
+				" ______________ \n" +
+				"/ Notes        \\\n" +
+				"| ------------ |\n" +
+				"| - long words |\n" +
+				"|   wrap under |\n" +
+				"|   the item   |\n" +
+				"\\ 1. numbered  /\n" +
+				" -------------- \n",
+		},
+		{
+			name: "rule by the balloon style",
+			opts: []Option{WithBalloonStyle(RoundedBalloon), DisableWordWrap()},
+			want: "" +
+				"╭──────────────────────────────────╮\n" +
+				"│ Notes                            │\n" +
+				"│ ──────────────────────────────── │\n" +
+				"│ - long words wrap under the item │\n" +
+				"│ 1. numbered                      │\n" +
+				"╰──────────────────────────────────╯\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{FromString(" $thoughts"), Markdown(), BallonWidth(14)}, tt.opts...)
+			bone, err := New(opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := bone.Balloon(phrase); tt.want != got {
+				t.Errorf("want\n%s\n-----got\n%s", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package bonesay
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"unicode/utf8"
+)
+
+// Mood is the face of the bone, that is, the pair of eyes and tongue.
+// They are adjusted in the same way as Eyes and Tongue.
+type Mood struct {
+	Eyes   string
+	Tongue string
+}
+
+// Built-in moods which are the modes of the original cowsay.
+var (
+	// BorgMood is the face of the Borg.
+	BorgMood = Mood{Eyes: "==", Tongue: "  "}
+	// DeadMood is the face of the dead bone.
+	DeadMood = Mood{Eyes: "xx", Tongue: "U "}
+	// GreedyMood is the face of the greedy bone.
+	GreedyMood = Mood{Eyes: "$$", Tongue: "  "}
+	// ParanoidMood is the face of the paranoid bone.
+	ParanoidMood = Mood{Eyes: "@@", Tongue: "  "}
+	// StonedMood is the face of the stoned bone.
+	StonedMood = Mood{Eyes: "**", Tongue: "U "}
+	// TiredMood is the face of the tired bone.
+	TiredMood = Mood{Eyes: "--", Tongue: "  "}
+	// WiredMood is the face of the wired bone.
+	WiredMood = Mood{Eyes: "OO", Tongue: "  "}
+	// YouthfulMood is the face of the youthful bone.
+	YouthfulMood = Mood{Eyes: "..", Tongue: "  "}
+)
+
+var moods = struct {
+	mu sync.RWMutex
+	m  map[string]Mood
+}{
+	m: map[string]Mood{
+		"borg":     BorgMood,
+		"dead":     DeadMood,
+		"greedy":   GreedyMood,
+		"paranoid": ParanoidMood,
+		"stoned":   StonedMood,
+		"tired":    TiredMood,
+		"wired":    WiredMood,
+		"youthful": YouthfulMood,
+	},
+}
+
+// RegisterMood registers the mood which is named name, so that it is
+// available to LookupMood and Moods. If name is already registered,
+// including the built-in moods, it is replaced.
+func RegisterMood(name string, mood Mood) error {
+	if name == "" {
+		return fmt.Errorf("invalid mood name %q", name)
+	}
+	if !utf8.ValidString(mood.Eyes) || !utf8.ValidString(mood.Tongue) {
+		return fmt.Errorf("invalid mood %q, the eyes and the tongue must be valid UTF-8", name)
+	}
+	moods.mu.Lock()
+	defer moods.mu.Unlock()
+	moods.m[name] = mood
+	return nil
+}
+
+// LookupMood returns the mood by name. See Moods for the names.
+func LookupMood(name string) (Mood, bool) {
+	moods.mu.RLock()
+	defer moods.mu.RUnlock()
+	mood, ok := moods.m[name]
+	return mood, ok
+}
+
+// Moods returns the sorted names of the built-in and registered moods.
+func Moods() []string {
+	moods.mu.RLock()
+	defer moods.mu.RUnlock()
+	names := make([]string, 0, len(moods.m))
+	for name := range moods.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithMood specifies the eyes and the tongue by the mood.
+// It overrides Eyes and Tongue which are specified before, and vice versa.
+func WithMood(mood Mood) Option {
+	return func(c *Bone) error {
+		if err := Eyes(mood.Eyes)(c); err != nil {
+			return err
+		}
+		return Tongue(mood.Tongue)(c)
+	}
+}
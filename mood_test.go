@@ -0,0 +1,133 @@
+package bonesay
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWithMood(t *testing.T) {
+	source := NewSource("mood", fstest.MapFS{
+		"face.bone": {Data: []byte("$the_bone = <<EOB;\n($eyes)\n $tongue\nEOB\n")},
+	})
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "dead",
+			opts: []Option{WithMood(DeadMood)},
+			want: "(xx)\n U ",
+		},
+		{
+			name: "overrides eyes",
+			opts: []Option{Eyes("^^"), WithMood(BorgMood)},
+			want: "(==)\n   ",
+		},
+		{
+			name: "overridden by eyes",
+			opts: []Option{WithMood(StonedMood), Eyes("^^")},
+			want: "(^^)\n U ",
+		},
+		{
+			name: "wide characters",
+			opts: []Option{WithMood(Mood{Eyes: "👀"})},
+			want: "(👀)\n   ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bone, err := New(append([]Option{WithSources(source), Type("face")}, tt.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := bone.GetBone()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want != got {
+				t.Errorf("want\n%s\n-----got\n%s", tt.want, got)
+			}
+		})
+	}
+}
+
+func useTestMoods(t *testing.T) {
+	t.Helper()
+	moods.mu.Lock()
+	old := moods.m
+	m := make(map[string]Mood, len(old))
+	for name, mood := range old {
+		m[name] = mood
+	}
+	moods.m = m
+	moods.mu.Unlock()
+	t.Cleanup(func() {
+		moods.mu.Lock()
+		moods.m = old
+		moods.mu.Unlock()
+	})
+}
+
+func TestRegisterMood(t *testing.T) {
+	useTestMoods(t)
+
+	want := Mood{Eyes: "^^", Tongue: "u "}
+	if err := RegisterMood("test-happy", want); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := LookupMood("test-happy")
+	if !ok {
+		t.Fatal("registered mood is not found")
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want, +got)\n%s", diff)
+	}
+
+	found := false
+	for _, name := range Moods() {
+		if name == "test-happy" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("registered mood is not in %v", Moods())
+	}
+
+	if err := RegisterMood("", want); err == nil {
+		t.Error("want error for empty name, but got nil")
+	}
+	if err := RegisterMood("test-broken", Mood{Eyes: "\xff"}); err == nil {
+		t.Error("want error for invalid UTF-8, but got nil")
+	}
+	if _, ok := LookupMood("test-broken"); ok {
+		t.Error("invalid mood is registered")
+	}
+}
+
+func TestLookupMood(t *testing.T) {
+	for name, want := range map[string]Mood{
+		"borg":     BorgMood,
+		"dead":     DeadMood,
+		"greedy":   GreedyMood,
+		"paranoid": ParanoidMood,
+		"stoned":   StonedMood,
+		"tired":    TiredMood,
+		"wired":    WiredMood,
+		"youthful": YouthfulMood,
+	} {
+		got, ok := LookupMood(name)
+		if !ok {
+			t.Errorf("%s is not found", name)
+			continue
+		}
+		if want != got {
+			t.Errorf("%s: want %+v, but got %+v", name, want, got)
+		}
+	}
+	if _, ok := LookupMood("unknown"); ok {
+		t.Error("unknown mood is found")
+	}
+}
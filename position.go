@@ -0,0 +1,154 @@
+package bonesay
+
+import (
+	"context"
+	"strings"
+)
+
+// BalloonPosition is the position of the balloon relative to the bone.
+type BalloonPosition int
+
+// BalloonPosition values.
+const (
+	// BalloonDefault places the balloon at $balloonPosition of the
+	// bonefile, or above the bone if the bonefile does not declare it.
+	BalloonDefault BalloonPosition = iota
+	// BalloonAbove places the balloon above the bone at $ballonOffset of
+	// the bonefile. The tail is drawn by $thoughts in the bonefile.
+	BalloonAbove
+	// BalloonBelow places the balloon below the bone at $ballonOffset of
+	// the bonefile.
+	BalloonBelow
+	// BalloonLeft places the balloon on the left of the bone.
+	BalloonLeft
+	// BalloonRight places the balloon on the right of the bone.
+	BalloonRight
+)
+
+func (p BalloonPosition) String() string {
+	switch p {
+	case BalloonDefault:
+		return "default"
+	case BalloonAbove:
+		return "above"
+	case BalloonBelow:
+		return "below"
+	case BalloonLeft:
+		return "left"
+	case BalloonRight:
+		return "right"
+	}
+	return "unknown"
+}
+
+// balloonPositions are the values of $balloonPosition in bonefiles.
+var balloonPositions = map[string]BalloonPosition{
+	"above": BalloonAbove,
+	"below": BalloonBelow,
+	"left":  BalloonLeft,
+	"right": BalloonRight,
+}
+
+// WithBalloonPosition specifies the position of the balloon relative to
+// the bone. Except for BalloonAbove, $thoughts in the bonefile is drawn as
+// a space and the tail is drawn toward the bone instead.
+//
+// The default position can be declared in the bonefile:
+//
+//	$balloonPosition = "right";
+func WithBalloonPosition(p BalloonPosition) Option {
+	return func(c *Bone) error {
+		c.balloonPosition = p
+		return nil
+	}
+}
+
+// tailRune returns the rune of the tail which is drawn toward the bone.
+// slash is used if the thoughts of the bone is a slash or a backslash,
+// which points to a particular direction.
+func (bone *Bone) tailRune(slash rune) rune {
+	if bone.thoughts == '/' || bone.thoughts == '\\' {
+		return slash
+	}
+	return bone.thoughts
+}
+
+// renderBelow writes the bone and the balloon below it.
+func (bone *Bone) renderBelow(w *lineWriter, phrase string, a *art) {
+	w.WriteString(a.text)
+	w.WriteRune('\n')
+	// The tail goes down to the text in the balloon.
+	tail := bone.tailRune('\\')
+	width, offset := bone.placeBalloon(a.balloonOffset)
+	for i := 1; i <= 2; i++ {
+		writeSpaces(w, offset+i)
+		w.WriteRune(tail)
+		w.WriteRune('\n')
+	}
+	bone.renderBalloon(w, phrase, width, offset)
+}
+
+// renderBeside writes the bone and the balloon side by side. The top of
+// the balloon is aligned to the top of the bone, and the tail points from
+// the first line of the text.
+func (bone *Bone) renderBeside(w *lineWriter, phrase string, a *art) {
+	artLines := strings.Split(strings.TrimSuffix(a.text, "\n"), "\n")
+	artWidth := maxVisibleWidth(artLines)
+
+	// The balloon is separated from the bone by the tail between spaces.
+	var buf strings.Builder
+	bw := newLineWriter(context.Background(), &buf)
+	bone.renderBalloon(bw, phrase, bone.wrapWidth(artWidth+4), 1)
+	bw.Flush()
+	balloonLines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+
+	left, right := artLines, balloonLines
+	leftWidth := artWidth
+	tail := bone.tailRune('<')
+	if a.position == BalloonLeft {
+		left, right = balloonLines, artLines
+		leftWidth = maxVisibleWidth(balloonLines)
+		tail = bone.tailRune('>')
+	}
+	tailLine := bone.paddingY
+	if bone.balloonStyle.Top != 0 {
+		tailLine++
+	}
+
+	n := len(left)
+	if len(right) > n {
+		n = len(right)
+	}
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		b.Reset()
+		var l, r string
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		b.WriteString(l)
+		b.WriteString(strings.Repeat(" ", leftWidth-visibleWidth(l)+1))
+		if i == tailLine {
+			b.WriteRune(tail)
+		} else {
+			b.WriteByte(' ')
+		}
+		b.WriteByte(' ')
+		b.WriteString(r)
+		w.WriteString(strings.TrimRight(b.String(), " "))
+		w.WriteRune('\n')
+	}
+}
+
+func maxVisibleWidth(lines []string) int {
+	width := 0
+	for _, line := range lines {
+		if w := visibleWidth(line); w > width {
+			width = w
+		}
+	}
+	return width
+}
@@ -0,0 +1,102 @@
+package bonesay
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/anthonycuervo23/bonesay/v2/bonefile"
+)
+
+func TestWithBalloonPosition(t *testing.T) {
+	source := NewSource("position", fstest.MapFS{
+		"face.bone":  {Data: []byte("$the_bone = <<EOB;\n  $thoughts\n   $thoughts\n  ($eyes)\n  (__)\nEOB\n")},
+		"right.bone": {Data: []byte("$balloonPosition = \"right\";\n$the_bone = <<EOB;\n$thoughts\n($eyes)\nEOB\n")},
+	})
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "above",
+			opts: []Option{Type("face")},
+			want: "" +
+				" ____ \n" +
+				"< hi >\n" +
+				" ---- \n" +
+				"  /\n" +
+				"   /\n" +
+				"  (oo)\n" +
+				"  (__)",
+		},
+		{
+			name: "below",
+			opts: []Option{Type("face"), WithBalloonPosition(BalloonBelow)},
+			want: "" +
+				"  (oo)\n" +
+				"  (__)\n" +
+				"  \\\n" +
+				"   \\\n" +
+				" ____ \n" +
+				"< hi >\n" +
+				" ---- \n",
+		},
+		{
+			name: "right",
+			opts: []Option{Type("face"), WithBalloonPosition(BalloonRight)},
+			want: "" +
+				"(oo)    ____\n" +
+				"(__) < < hi >\n" +
+				"        ----\n",
+		},
+		{
+			name: "left",
+			opts: []Option{Type("face"), WithBalloonPosition(BalloonLeft), Thoughts('o')},
+			want: "" +
+				" ____    (oo)\n" +
+				"< hi > o (__)\n" +
+				" ----\n",
+		},
+		{
+			name: "declared in bonefile",
+			opts: []Option{Type("right")},
+			want: "" +
+				"(oo)    ____\n" +
+				"     < < hi >\n" +
+				"        ----\n",
+		},
+		{
+			name: "option overrides bonefile",
+			opts: []Option{Type("right"), WithBalloonPosition(BalloonAbove)},
+			want: "" +
+				" ____ \n" +
+				"< hi >\n" +
+				" ---- \n" +
+				"/\n" +
+				"(oo)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Say("hi", append([]Option{WithSources(source)}, tt.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want != got {
+				t.Errorf("want\n%s\n-----got\n%s", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		source := NewSource("invalid", fstest.MapFS{
+			"up.bone": {Data: []byte("$balloonPosition = \"up\";\n$the_bone = <<EOB;\n($eyes)\nEOB\n")},
+		})
+		_, err := Say("hi", WithSources(source), Type("up"))
+		var bfErr *bonefile.Error
+		if !errors.As(err, &bfErr) {
+			t.Errorf("want *bonefile.Error, but got %v", err)
+		}
+	})
+}
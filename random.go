@@ -0,0 +1,218 @@
+package bonesay
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"path"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// globalRand is used by Random when Seed is not specified.
+// It does not touch the global source of math/rand.
+var globalRand = rand.New(&lockedSource{
+	src: rand.NewSource(time.Now().UnixNano()).(rand.Source64),
+})
+
+// lockedSource is a rand.Source which is safe for concurrent use.
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source64
+}
+
+var _ rand.Source64 = (*lockedSource)(nil)
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
+
+// ErrNoCandidates is returned by Random when there are no bonefiles to pick.
+var ErrNoCandidates = errors.New("no bonefiles to pick at random")
+
+// RandomOption specifies how Random picks the bonefile.
+type RandomOption func(*randomOptions)
+
+type randomOptions struct {
+	include []string
+	exclude []string
+	tags    []string
+	weights map[string]float64
+}
+
+// Include picks only the bonefiles whose names match any of the patterns.
+// The syntax of the patterns is the same as path.Match.
+func Include(patterns ...string) RandomOption {
+	return func(o *randomOptions) {
+		o.include = append(o.include, patterns...)
+	}
+}
+
+// Exclude does not pick the bonefiles whose names match any of the patterns.
+// The syntax of the patterns is the same as path.Match.
+func Exclude(patterns ...string) RandomOption {
+	return func(o *randomOptions) {
+		o.exclude = append(o.exclude, patterns...)
+	}
+}
+
+// Tagged picks only the bonefiles which have any of the tags.
+//
+// The tags of a bonefile are written in the $tags variable, and separated
+// by commas or spaces. e.g.
+//
+//	$tags = "animal, linux";
+//
+// A bonefile which cannot be parsed is not picked.
+func Tagged(tags ...string) RandomOption {
+	return func(o *randomOptions) {
+		o.tags = append(o.tags, tags...)
+	}
+}
+
+// Weights specifies the relative weights of the bonefiles by name.
+// The weight of a bonefile which is not in weights is 1, and a bonefile
+// whose weight is 0 is not picked. Negative weights are errors.
+func Weights(weights map[string]float64) RandomOption {
+	return func(o *randomOptions) {
+		if o.weights == nil {
+			o.weights = make(map[string]float64, len(weights))
+		}
+		for name, weight := range weights {
+			o.weights[name] = weight
+		}
+	}
+}
+
+func (o *randomOptions) weight(name string) float64 {
+	if weight, ok := o.weights[name]; ok {
+		return weight
+	}
+	return 1
+}
+
+// match reports whether the bonefile can be picked.
+func (o *randomOptions) match(bf *BoneFile) (bool, error) {
+	if len(o.include) > 0 {
+		ok, err := matchAny(o.include, bf.Name)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	ok, err := matchAny(o.exclude, bf.Name)
+	if err != nil || ok {
+		return false, err
+	}
+	if len(o.tags) > 0 && !hasAnyTag(bf, o.tags) {
+		return false, nil
+	}
+	return o.weight(bf.Name) > 0, nil
+}
+
+func matchAny(patterns []string, name string) (bool, error) {
+	for _, pattern := range patterns {
+		ok, err := path.Match(pattern, name)
+		if err != nil {
+			return false, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func hasAnyTag(bf *BoneFile, tags []string) bool {
+	t, err := templates.load(bf)
+	if err != nil {
+		return false
+	}
+	d := t.file.Directive("tags")
+	if d == nil {
+		return false
+	}
+	value := strings.Trim(d.Value, `"'`)
+	for _, tag := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	}) {
+		for _, want := range tags {
+			if tag == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pickBone picks a bonefile from the bonefiles which are resolved in
+// bonePaths. Each bonefile is picked with the probability in proportion to
+// its weight.
+func pickBone(r *rand.Rand, bonePaths []*BonePath, opts []RandomOption) (*BoneFile, error) {
+	var o randomOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	for name, weight := range o.weights {
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("invalid weight %v for %q", weight, name)
+		}
+	}
+
+	var (
+		candidates []*BoneFile
+		total      float64
+		seen       = make(map[string]bool)
+	)
+	for _, bonePath := range bonePaths {
+		if bonePath.Err != nil {
+			continue
+		}
+		for _, name := range bonePath.Names() {
+			if seen[name] {
+				// shadowed by the former one
+				continue
+			}
+			seen[name] = true
+			bf, _ := bonePath.Lookup(name)
+			ok, err := o.match(bf)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				candidates = append(candidates, bf)
+				total += o.weight(name)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, ErrNoCandidates
+	}
+	if o.weights == nil {
+		return candidates[r.Intn(len(candidates))], nil
+	}
+	x := r.Float64() * total
+	for _, bf := range candidates {
+		x -= o.weight(bf.Name)
+		if x < 0 {
+			return bf, nil
+		}
+	}
+	return candidates[len(candidates)-1], nil
+}
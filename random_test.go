@@ -0,0 +1,132 @@
+package bonesay
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRandom(t *testing.T) {
+	bone := func(tags string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte("$tags = " + tags + ";\n$the_bone = <<EOB;\n$eyes\nEOB\n")}
+	}
+	// "many" has many bonefiles and "one" has only one.
+	many := NewSource("many", fstest.MapFS{
+		"a1.bone":     bone(`"animal"`),
+		"a2.bone":     bone(`"animal, linux"`),
+		"a3.bone":     bone(`animal`),
+		"shadow.bone": bone(`"many"`),
+		"b1.bone":     bone(`"linux"`),
+		"b2.cow":      {Data: []byte("$the_bone = <<EOC;\n$eyes\nEOC\n")},
+	})
+	one := NewSource("one", fstest.MapFS{
+		"shadow.bone": bone(`"one"`),
+		"c1.bone":     bone(`"other"`),
+	})
+	empty := NewSource("empty", fstest.MapFS{})
+
+	count := func(t *testing.T, n int, opts ...RandomOption) map[string]int {
+		t.Helper()
+		r := rand.New(rand.NewSource(1))
+		counts := make(map[string]int)
+		for i := 0; i < n; i++ {
+			bone, err := New(WithSources(empty, many, one), RandomWithSource(r, opts...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			counts[bone.typ.BasePath+":"+bone.typ.Name]++
+		}
+		return counts
+	}
+
+	t.Run("uniform", func(t *testing.T) {
+		const n = 7000
+		counts := count(t, n)
+		want := []string{"many:a1", "many:a2", "many:a3", "many:shadow", "many:b1", "many:b2", "one:c1"}
+		if len(counts) != len(want) {
+			t.Fatalf("want %v, but got %v", want, counts)
+		}
+		for _, name := range want {
+			// 1000 is expected for each.
+			if got := counts[name]; got < 850 || got > 1150 {
+				t.Errorf("%s is picked %d times in %d", name, got, n)
+			}
+		}
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		tests := []struct {
+			name string
+			opts []RandomOption
+			want []string
+		}{
+			{
+				name: "include",
+				opts: []RandomOption{Include("b*", "c?")},
+				want: []string{"many:b1", "many:b2", "one:c1"},
+			},
+			{
+				name: "exclude",
+				opts: []RandomOption{Exclude("a*", "b*")},
+				want: []string{"many:shadow", "one:c1"},
+			},
+			{
+				name: "tagged",
+				opts: []RandomOption{Tagged("linux")},
+				want: []string{"many:a2", "many:b1"},
+			},
+			{
+				name: "shadowed one is not tagged",
+				opts: []RandomOption{Tagged("one", "other")},
+				want: []string{"one:c1"},
+			},
+			{
+				name: "weight 0",
+				opts: []RandomOption{Include("a*"), Weights(map[string]float64{"a1": 0, "a3": 0})},
+				want: []string{"many:a2"},
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				counts := count(t, 100, tt.opts...)
+				if len(counts) != len(tt.want) {
+					t.Errorf("want %v, but got %v", tt.want, counts)
+				}
+				for _, name := range tt.want {
+					if counts[name] == 0 {
+						t.Errorf("want %s, but got %v", name, counts)
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("weights", func(t *testing.T) {
+		counts := count(t, 4000, Include("a1", "a2"), Weights(map[string]float64{"a1": 3}))
+		// 3000 and 1000 are expected.
+		if got := counts["many:a1"]; got < 2850 || got > 3150 {
+			t.Errorf("a1 is picked %d times in 4000", got)
+		}
+	})
+
+	t.Run("no candidates", func(t *testing.T) {
+		_, err := New(WithSources(empty), Random())
+		if !errors.Is(err, ErrNoCandidates) {
+			t.Errorf("want %v, but got %v", ErrNoCandidates, err)
+		}
+		_, err = New(WithSources(many), Random(Include("zzz")))
+		if !errors.Is(err, ErrNoCandidates) {
+			t.Errorf("want %v, but got %v", ErrNoCandidates, err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := New(WithSources(many), Random(Include("["))); err == nil {
+			t.Error("want error for invalid pattern")
+		}
+		if _, err := New(WithSources(many), Random(Weights(map[string]float64{"a1": -1}))); err == nil {
+			t.Error("want error for negative weight")
+		}
+	})
+}
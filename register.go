@@ -0,0 +1,255 @@
+package bonesay
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anthonycuervo23/bonesay/v2/bonefile"
+)
+
+var registry = newMemSource("registered")
+
+// Register registers the art as the bonefile which is named name.
+// The registered bone is available to Type, Random and Bones.
+// If name is already registered, it is replaced.
+//
+// art is the picture of the bone. It is written in the same way as the
+// heredoc body in the bonefile, so $eyes, $tongue and $thoughts are
+// available as placeholders and "\\", "\@" and "\$" are escapes.
+// Other variables are the slots which are filled by Vars, and they must be
+// written with braces such as ${hat}. If art refers to an unknown
+// placeholder such as $hat, it returns *bonefile.Error whose position is
+// relative to art.
+func Register(name, art string) error {
+	if name == "" || strings.ContainsAny(name, `/\`) || !fs.ValidPath(name) {
+		return fmt.Errorf("invalid bonefile name %q", name)
+	}
+	if err := validateArt(name, art); err != nil {
+		return err
+	}
+	registry.store(name+BoneFormat.Suffix(), wrapArt(art))
+	return nil
+}
+
+// RegisteredSource returns the BoneSource of the bones which are registered
+// by Register. It can be used with WithSources.
+func RegisteredSource() BoneSource {
+	return registry
+}
+
+// FromString specifies the art as the bonefile.
+// The art is written in the same way as Register.
+func FromString(art string) Option {
+	return func(c *Bone) error {
+		const name = "string"
+		if err := validateArt(name, art); err != nil {
+			return err
+		}
+		source := newMemSource("memory")
+		source.store(name+BoneFormat.Suffix(), wrapArt(art))
+		c.resolve = nil
+		c.typ = &BoneFile{
+			Name:         name,
+			BasePath:     source.Name(),
+			LocationType: InMemory,
+			Source:       source,
+		}
+		return nil
+	}
+}
+
+// validateArt reports whether art can be read as the heredoc body. A
+// variable without braces must be one of the placeholders, so that a typo
+// such as $thougts is not taken as a slot.
+func validateArt(name string, art string) error {
+	f, err := bonefile.ParseBody(name, []byte(art), 0)
+	if err != nil {
+		return err
+	}
+	for _, seg := range f.Body.Segments {
+		v, ok := seg.(*bonefile.Variable)
+		if !ok || v.Braced || builtinVars[v.Name] {
+			continue
+		}
+		return &bonefile.Error{
+			Filename: name,
+			Pos:      v.NamePos,
+			Msg:      fmt.Sprintf("unknown placeholder $%s, slots must be written as ${%s}", v.Name, v.Name),
+		}
+	}
+	return nil
+}
+
+// artError converts the position of err in the bonefile which is wrapped
+// by wrapArt to the position in the art.
+func artError(bf *BoneFile, err error) error {
+	var bfErr *bonefile.Error
+	if bf.LocationType != InMemory || !errors.As(err, &bfErr) {
+		return err
+	}
+	e := *bfErr
+	e.Pos.Line--
+	return &e
+}
+
+// wrapArt wraps the art with the heredoc so that it can be read as a bonefile.
+func wrapArt(art string) []byte {
+	delim := "EOB"
+	for i := 1; containsLine(art, delim); i++ {
+		delim = "EOB" + strconv.Itoa(i)
+	}
+	return []byte("$the_bone = <<" + delim + ";\n" + art + "\n" + delim + "\n")
+}
+
+func containsLine(s, line string) bool {
+	for _, l := range strings.Split(s, "\n") {
+		if strings.TrimRight(l, " \t\r") == line {
+			return true
+		}
+	}
+	return false
+}
+
+// memSource is a BoneSource which holds bonefiles in memory.
+type memSource struct {
+	name  string
+	mu    sync.RWMutex
+	files map[string]*memFileInfo
+}
+
+var _ interface {
+	BoneSource
+	fs.ReadFileFS
+} = (*memSource)(nil)
+
+func newMemSource(name string) *memSource {
+	return &memSource{
+		name:  name,
+		files: make(map[string]*memFileInfo),
+	}
+}
+
+func (m *memSource) Name() string { return m.name }
+
+func (m *memSource) store(name string, data []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	modTime := time.Now()
+	// The modification time always advances so that the cached template
+	// of the replaced bonefile is not used.
+	if old, ok := m.files[name]; ok && !modTime.After(old.modTime) {
+		modTime = old.modTime.Add(time.Nanosecond)
+	}
+	m.files[name] = &memFileInfo{
+		name:    name,
+		data:    data,
+		modTime: modTime,
+	}
+}
+
+func (m *memSource) len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.files)
+}
+
+func (m *memSource) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if name == "." {
+		entries := make([]fs.DirEntry, 0, len(m.files))
+		for _, info := range m.files {
+			entries = append(entries, fs.FileInfoToDirEntry(info))
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].Name() < entries[j].Name()
+		})
+		return &memDir{entries: entries}, nil
+	}
+	info, ok := m.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &memFile{
+		info:   info,
+		Reader: bytes.NewReader(info.data),
+	}, nil
+}
+
+func (m *memSource) ReadFile(name string) ([]byte, error) {
+	f, err := m.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+type memFileInfo struct {
+	name    string
+	data    []byte
+	modTime time.Time
+}
+
+func (i *memFileInfo) Name() string       { return i.name }
+func (i *memFileInfo) Size() int64        { return int64(len(i.data)) }
+func (i *memFileInfo) Mode() fs.FileMode  { return 0444 }
+func (i *memFileInfo) ModTime() time.Time { return i.modTime }
+func (i *memFileInfo) IsDir() bool        { return false }
+func (i *memFileInfo) Sys() interface{}   { return nil }
+
+type memFile struct {
+	*bytes.Reader
+	info *memFileInfo
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memFile) Close() error               { return nil }
+
+type memDir struct {
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return memDirInfo{}, nil }
+func (d *memDir) Close() error               { return nil }
+
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: ".", Err: fs.ErrInvalid}
+}
+
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.offset += n
+	return rest[:n], nil
+}
+
+type memDirInfo struct{}
+
+func (memDirInfo) Name() string       { return "." }
+func (memDirInfo) Size() int64        { return 0 }
+func (memDirInfo) Mode() fs.FileMode  { return fs.ModeDir | 0555 }
+func (memDirInfo) ModTime() time.Time { return time.Time{} }
+func (memDirInfo) IsDir() bool        { return true }
+func (memDirInfo) Sys() interface{}   { return nil }
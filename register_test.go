@@ -0,0 +1,141 @@
+package bonesay
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/anthonycuervo23/bonesay/v2/bonefile"
+)
+
+func useTestRegistry(t *testing.T) {
+	t.Helper()
+	old := registry
+	registry = newMemSource("registered")
+	t.Cleanup(func() { registry = old })
+}
+
+func TestRegister(t *testing.T) {
+	useTestRegistry(t)
+
+	art := "  $thoughts\n   ($eyes)\nEOB\n    $tongue \\\\"
+	if err := Register("mascot", art); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("type", func(t *testing.T) {
+		got, err := Say("hi", Type("mascot"), Eyes("^^"), Tongue("U"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := " ____ \n< hi >\n ---- \n  /\n   (^^)\nEOB\n    U  \\"
+		if want != got {
+			t.Errorf("want %q, but got %q", want, got)
+		}
+	})
+
+	t.Run("bones", func(t *testing.T) {
+		isolateXDG(t)
+		t.Setenv("BONEPATH", "")
+		bonePaths, err := Bones()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(bonePaths) != 2 {
+			t.Fatalf("want 2, but got %d", len(bonePaths))
+		}
+		if bonePaths[0].LocationType != InMemory {
+			t.Errorf("want %v, but got %v", InMemory, bonePaths[0].LocationType)
+		}
+		if _, ok := bonePaths[0].Lookup("mascot"); !ok {
+			t.Errorf("want mascot in %v", bonePaths[0].BoneFiles)
+		}
+	})
+
+	t.Run("random", func(t *testing.T) {
+		bone, err := New(WithSources(RegisteredSource()), Random())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bone.typ.Name != "mascot" {
+			t.Errorf("want %q, but got %q", "mascot", bone.typ.Name)
+		}
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		for _, name := range []string{"", "a/b", "..", `a\b`} {
+			if err := Register(name, "art"); err == nil {
+				t.Errorf("want error for %q", name)
+			}
+		}
+	})
+
+	t.Run("slots", func(t *testing.T) {
+		if err := Register("badge", "  $thoughts\n  [${hat}]"); err != nil {
+			t.Fatal(err)
+		}
+		bone, err := New(WithSources(RegisteredSource()), Type("badge"), Vars(map[string]string{"hat": "^^"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := bone.GetBone()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "  /\n  [^^]"; want != got {
+			t.Errorf("want %q, but got %q", want, got)
+		}
+
+		// The slot which is not filled is an error on rendering.
+		_, err = Say("hi", WithSources(RegisteredSource()), Type("badge"))
+		var bferr *bonefile.Error
+		if !errors.As(err, &bferr) {
+			t.Fatalf("want *bonefile.Error, but got %v", err)
+		}
+		if bferr.Pos.Line != 2 {
+			t.Errorf("want the error at line 2 of the art, but got %v", bferr)
+		}
+	})
+
+	t.Run("misspelled placeholder", func(t *testing.T) {
+		err := Register("typo", " $thougts\n")
+		var bferr *bonefile.Error
+		if !errors.As(err, &bferr) {
+			t.Fatalf("want *bonefile.Error, but got %v", err)
+		}
+		if bferr.Pos.Line != 1 || bferr.Pos.Column != 2 {
+			t.Errorf("want the error at 1:2, but got %v", bferr)
+		}
+		if _, err := New(WithSources(RegisteredSource()), Type("typo")); err == nil {
+			t.Error("invalid art is registered")
+		}
+	})
+}
+
+func TestFromString(t *testing.T) {
+	got, err := Say("hi", FromString(" $thoughts ($eyes)"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := " ____ \n< hi >\n ---- \n / (oo)"
+	if want != got {
+		t.Errorf("want %q, but got %q", want, got)
+	}
+
+	got, err = Say("hi", FromString(" $thoughts [${hat}]"), Vars(map[string]string{"hat": "^^"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = " ____ \n< hi >\n ---- \n / [^^]"
+	if want != got {
+		t.Errorf("want %q, but got %q", want, got)
+	}
+
+	_, err = New(FromString(" $thoughts\n ($eyse)"))
+	var bferr *bonefile.Error
+	if !errors.As(err, &bferr) {
+		t.Fatalf("want *bonefile.Error, but got %v", err)
+	}
+	if want := "string:2:3: unknown placeholder $eyse, slots must be written as ${eyse}"; want != bferr.Error() {
+		t.Errorf("want %q, but got %q", want, bferr.Error())
+	}
+}
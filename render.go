@@ -0,0 +1,101 @@
+package bonesay
+
+import (
+	"bytes"
+	"context"
+	"io"
+)
+
+// Render writes the balloon and the bone to w.
+//
+// The output is written line by line, so it can be streamed to a slow
+// writer such as a network connection. Render checks ctx before writing
+// each line and stops with ctx.Err() if ctx is done.
+//
+// It returns the number of bytes written to w.
+func (bone *Bone) Render(ctx context.Context, w io.Writer, phrase string) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	a, err := bone.loadArt()
+	if err != nil {
+		return 0, err
+	}
+	lw := newLineWriter(ctx, w)
+	switch a.position {
+	case BalloonBelow:
+		bone.renderBelow(lw, phrase, a)
+	case BalloonLeft, BalloonRight:
+		bone.renderBeside(lw, phrase, a)
+	default:
+		width, offset := bone.placeBalloon(a.balloonOffset)
+		bone.renderBalloon(lw, phrase, width, offset)
+		lw.WriteString(a.text)
+	}
+	lw.Flush()
+	return lw.n, lw.err
+}
+
+// lineWriter buffers the output and writes it to the underlying writer
+// line by line. Once an error is occurred, subsequent writes are ignored.
+type lineWriter struct {
+	ctx context.Context
+	w   io.Writer
+	buf bytes.Buffer
+	n   int64
+	err error
+}
+
+func newLineWriter(ctx context.Context, w io.Writer) *lineWriter {
+	return &lineWriter{ctx: ctx, w: w}
+}
+
+func (l *lineWriter) Write(p []byte) {
+	l.buf.Write(p)
+	l.flushLines()
+}
+
+func (l *lineWriter) WriteString(s string) {
+	l.buf.WriteString(s)
+	l.flushLines()
+}
+
+func (l *lineWriter) WriteRune(r rune) {
+	l.buf.WriteRune(r)
+	if r == '\n' {
+		l.flushLines()
+	}
+}
+
+// flushLines writes the completed lines in the buffer.
+func (l *lineWriter) flushLines() {
+	for l.err == nil {
+		i := bytes.IndexByte(l.buf.Bytes(), '\n')
+		if i < 0 {
+			return
+		}
+		l.writeOut(l.buf.Next(i + 1))
+	}
+	l.buf.Reset()
+}
+
+// Flush writes the rest of the buffer.
+func (l *lineWriter) Flush() {
+	l.flushLines()
+	if l.buf.Len() > 0 {
+		l.writeOut(l.buf.Next(l.buf.Len()))
+	}
+}
+
+func (l *lineWriter) writeOut(p []byte) {
+	if l.err != nil {
+		return
+	}
+	if err := l.ctx.Err(); err != nil {
+		l.err = err
+		return
+	}
+	n, err := l.w.Write(p)
+	l.n += int64(n)
+	l.err = err
+}
@@ -0,0 +1,88 @@
+package bonesay
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// lineRecorder records each write and calls hook after it.
+type lineRecorder struct {
+	writes []string
+	hook   func()
+}
+
+func (r *lineRecorder) Write(p []byte) (int, error) {
+	r.writes = append(r.writes, string(p))
+	if r.hook != nil {
+		r.hook()
+	}
+	return len(p), nil
+}
+
+func TestBone_Render(t *testing.T) {
+	bone, err := New(FromString(" $thoughts\n  ($eyes)"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("same as Say", func(t *testing.T) {
+		want, err := bone.Say("hello\nworld")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		n, err := bone.Render(context.Background(), &buf, "hello\nworld")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want != buf.String() {
+			t.Errorf("want %q, but got %q", want, buf.String())
+		}
+		if n != int64(len(want)) {
+			t.Errorf("want %d bytes, but got %d", len(want), n)
+		}
+	})
+
+	t.Run("line by line", func(t *testing.T) {
+		var r lineRecorder
+		if _, err := bone.Render(context.Background(), &r, "hi"); err != nil {
+			t.Fatal(err)
+		}
+		want := []string{" ____ \n", "< hi >\n", " ---- \n", " /\n", "  (oo)"}
+		if strings.Join(want, "|") != strings.Join(r.writes, "|") {
+			t.Errorf("want %q, but got %q", want, r.writes)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		r := &lineRecorder{hook: cancel}
+		n, err := bone.Render(ctx, r, "hi")
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("want %v, but got %v", context.Canceled, err)
+		}
+		if len(r.writes) != 1 || n != int64(len(r.writes[0])) {
+			t.Errorf("want only the first line, but got %d bytes %q", n, r.writes)
+		}
+
+		n, err = bone.Render(ctx, r, "hi")
+		if !errors.Is(err, context.Canceled) || n != 0 {
+			t.Errorf("want no output, but got %d bytes, %v", n, err)
+		}
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		wantErr := errors.New("error")
+		_, err := bone.Render(context.Background(), errWriter{wantErr}, "hi")
+		if !errors.Is(err, wantErr) {
+			t.Fatalf("want %v, but got %v", wantErr, err)
+		}
+	})
+}
+
+type errWriter struct{ err error }
+
+func (e errWriter) Write([]byte) (int, error) { return 0, e.err }
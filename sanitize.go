@@ -0,0 +1,145 @@
+package bonesay
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultTabWidth is the default interval of the tab stops.
+const defaultTabWidth = 8
+
+// SanitizePolicy is how the control characters in the phrase are treated.
+//
+// Newlines and tabs are not control characters here: "\r\n" is always
+// normalized to "\n", and tabs are expanded to the tab stops. Escape
+// sequences of colors (SGR) and hyperlinks (OSC 8) are kept unless the
+// policy is SanitizeEscape.
+type SanitizePolicy int
+
+// SanitizePolicy values.
+const (
+	// SanitizeStrip removes the control characters and the escape sequences
+	// other than colors and hyperlinks. It is the default.
+	SanitizeStrip SanitizePolicy = iota
+	// SanitizeEscape shows the control characters in caret notation,
+	// e.g. "^[" for ESC and "^H" for backspace, like "cat -v".
+	SanitizeEscape
+	// SanitizePass passes the control characters through to the output.
+	SanitizePass
+)
+
+func (p SanitizePolicy) String() string {
+	switch p {
+	case SanitizeStrip:
+		return "strip"
+	case SanitizeEscape:
+		return "escape"
+	case SanitizePass:
+		return "pass"
+	}
+	return "unknown"
+}
+
+// Sanitize specifies how the control characters in the phrase are treated.
+func Sanitize(policy SanitizePolicy) Option {
+	return func(c *Bone) error {
+		c.sanitize = policy
+		return nil
+	}
+}
+
+// TabWidth specifies the interval of the tab stops. The default is 8.
+// A tab is expanded to the spaces up to the next tab stop.
+// If n is 0, tabs are removed.
+func TabWidth(n uint) Option {
+	return func(c *Bone) error {
+		c.tabWidth = int(n)
+		return nil
+	}
+}
+
+// sanitizePhrase normalizes the newlines and treats the control characters
+// in the phrase by policy.
+func sanitizePhrase(phrase string, policy SanitizePolicy) string {
+	phrase = strings.Replace(phrase, "\r\n", "\n", -1)
+	if policy == SanitizePass {
+		return phrase
+	}
+	var b strings.Builder
+	b.Grow(len(phrase))
+	for i := 0; i < len(phrase); {
+		if n := escapeLen(phrase[i:]); n > 0 && policy == SanitizeStrip {
+			if seq := phrase[i : i+n]; isStyleSequence(seq) {
+				b.WriteString(seq)
+			}
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(phrase[i:])
+		i += size
+		if !isControl(r) {
+			b.WriteString(phrase[i-size : i])
+			continue
+		}
+		if policy == SanitizeEscape {
+			b.WriteString(caret(r))
+		}
+	}
+	return b.String()
+}
+
+// isStyleSequence reports whether seq is the escape sequence of colors
+// (SGR) or a hyperlink (OSC 8), which the balloon can handle.
+func isStyleSequence(seq string) bool {
+	return strings.HasPrefix(seq, "\x1b[") && strings.HasSuffix(seq, "m") ||
+		strings.HasPrefix(seq, "\x1b]8;")
+}
+
+// isControl reports whether r is a control character except for newlines
+// and tabs.
+func isControl(r rune) bool {
+	if r == '\n' || r == '\t' {
+		return false
+	}
+	return r < 0x20 || r == 0x7f || 0x80 <= r && r < 0xa0
+}
+
+// caret returns the control character in caret notation.
+// C1 control characters are prefixed with "M-" like "cat -v".
+func caret(r rune) string {
+	prefix := ""
+	if r >= 0x80 {
+		prefix, r = "M-", r-0x80
+	}
+	if r == 0x7f {
+		return prefix + "^?"
+	}
+	return prefix + "^" + string(r+'@')
+}
+
+// expandTabs expands the tabs in the line to the spaces up to the next tab
+// stop. The columns are counted in cells.
+func expandTabs(line string, tabWidth int) string {
+	if strings.IndexByte(line, '\t') < 0 {
+		return line
+	}
+	var b strings.Builder
+	col := 0
+	eachCluster(line, func(cluster string, escape bool) {
+		switch {
+		case escape:
+			b.WriteString(cluster)
+		case cluster == "\t":
+			if tabWidth <= 0 {
+				return
+			}
+			n := tabWidth - col%tabWidth
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+		default:
+			b.WriteString(cluster)
+			col += clusterWidth(cluster)
+		}
+	})
+	return b.String()
+}
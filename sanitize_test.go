@@ -0,0 +1,68 @@
+package bonesay
+
+import "testing"
+
+func TestSanitizePhrase(t *testing.T) {
+	const phrase = "a\r\nb\rc\bd\x00e\x1b[31mred\x1b[0m\x1b[2Jf\x7fg\u0085h"
+	tests := []struct {
+		policy SanitizePolicy
+		want   string
+	}{
+		{
+			policy: SanitizeStrip,
+			want:   "a\nbcde\x1b[31mred\x1b[0mfgh",
+		},
+		{
+			policy: SanitizeEscape,
+			want:   "a\nb^Mc^Hd^@e^[[31mred^[[0m^[[2Jf^?gM-^Eh",
+		},
+		{
+			policy: SanitizePass,
+			want:   "a\nb\rc\bd\x00e\x1b[31mred\x1b[0m\x1b[2Jf\x7fg\u0085h",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			if got := sanitizePhrase(phrase, tt.policy); tt.want != got {
+				t.Errorf("want %q, but got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestExpandTabs(t *testing.T) {
+	tests := []struct {
+		line     string
+		tabWidth int
+		want     string
+	}{
+		{line: "\tx", tabWidth: 8, want: "        x"},
+		{line: "abc\tx", tabWidth: 8, want: "abc     x"},
+		{line: "abcdefgh\tx", tabWidth: 8, want: "abcdefgh        x"},
+		{line: "a\tb\tc", tabWidth: 4, want: "a   b   c"},
+		{line: "漢字\tx", tabWidth: 8, want: "漢字    x"},
+		{line: "\x1b[31mab\x1b[0m\tx", tabWidth: 4, want: "\x1b[31mab\x1b[0m  x"},
+		{line: "a\tb", tabWidth: 0, want: "ab"},
+	}
+	for _, tt := range tests {
+		if got := expandTabs(tt.line, tt.tabWidth); tt.want != got {
+			t.Errorf("expandTabs(%q, %d) = %q, want %q", tt.line, tt.tabWidth, got, tt.want)
+		}
+	}
+}
+
+func TestBalloon_sanitize(t *testing.T) {
+	bone, err := New(FromString(" $thoughts"), TabWidth(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := bone.Balloon("key\tvalue\r\nk\tv\x1b[2K\x00")
+	want := "" +
+		" ___________ \n" +
+		"/ key value \\\n" +
+		"\\ k   v     /\n" +
+		" ----------- \n"
+	if want != got {
+		t.Errorf("want\n%q\ngot\n%q", want, got)
+	}
+}
@@ -0,0 +1,252 @@
+package bonesay
+
+import (
+	"context"
+	"io"
+	"strings"
+)
+
+// SceneLayout is how the turns in a Scene are laid out.
+type SceneLayout int
+
+// SceneLayout values.
+const (
+	// SceneTurns stacks the turns from top to bottom, and aligns them to
+	// the left and the right by turns like a chat.
+	SceneTurns SceneLayout = iota
+	// SceneSideBySide lays out the turns from left to right. The bones
+	// stand on the same line, and the turns which do not fit in the width
+	// are continued below.
+	SceneSideBySide
+)
+
+func (l SceneLayout) String() string {
+	switch l {
+	case SceneTurns:
+		return "turns"
+	case SceneSideBySide:
+		return "side-by-side"
+	}
+	return "unknown"
+}
+
+const (
+	// sceneGap is the number of columns between the turns side by side.
+	sceneGap = 2
+	// minTurnWidth is the minimum width of a turn side by side, so that
+	// the balloon is not too narrow to read.
+	minTurnWidth = 20
+)
+
+// Scene is a conversation of bones which is rendered as a single block of
+// text. Each turn is rendered by its own bone, so each turn can have its
+// own bonefile, eyes and balloon.
+type Scene struct {
+	layout   SceneLayout
+	maxWidth int
+	turns    []turn
+}
+
+type turn struct {
+	bone   *Bone
+	phrase string
+}
+
+// NewScene returns an empty scene.
+//
+// If maxWidth is positive, the balloons are fitted by FitWidth so that the
+// scene fits in maxWidth columns, unless the bones themselves are wider.
+func NewScene(layout SceneLayout, maxWidth int) *Scene {
+	return &Scene{
+		layout:   layout,
+		maxWidth: maxWidth,
+	}
+}
+
+// Add adds the turn in which bone says phrase. It returns s, so that the
+// calls can be chained.
+func (s *Scene) Add(bone *Bone, phrase string) *Scene {
+	s.turns = append(s.turns, turn{bone: bone, phrase: phrase})
+	return s
+}
+
+// Say returns the scene.
+//
+// See also Render.
+func (s *Scene) Say() (string, error) {
+	var buf strings.Builder
+	if _, err := s.Render(context.Background(), &buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Render writes the scene to w. It returns the number of bytes written
+// to w. See Bone.Render for ctx.
+func (s *Scene) Render(ctx context.Context, w io.Writer) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	var (
+		blocks []*sceneBlock
+		err    error
+	)
+	if s.layout == SceneSideBySide && s.maxWidth > 0 {
+		// The balloons are narrowed to put as many turns in a row as
+		// possible, but the bones cannot be narrowed.
+		for n := len(s.turns); n > 0; n-- {
+			width := (s.maxWidth - sceneGap*(n-1)) / n
+			if width < minTurnWidth && n > 1 {
+				continue
+			}
+			blocks, err = s.render(ctx, width)
+			if err != nil {
+				return 0, err
+			}
+			if fits(blocks, width) {
+				break
+			}
+		}
+	} else {
+		blocks, err = s.render(ctx, s.maxWidth)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	lw := newLineWriter(ctx, w)
+	switch s.layout {
+	case SceneSideBySide:
+		s.writeSideBySide(lw, blocks)
+	default:
+		s.writeTurns(lw, blocks)
+	}
+	lw.Flush()
+	return lw.n, lw.err
+}
+
+// render renders the turns whose balloons are fitted in width.
+func (s *Scene) render(ctx context.Context, width int) ([]*sceneBlock, error) {
+	blocks := make([]*sceneBlock, 0, len(s.turns))
+	for _, t := range s.turns {
+		b, err := t.render(ctx, width)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, nil
+}
+
+// fits reports whether every block fits in width.
+func fits(blocks []*sceneBlock, width int) bool {
+	for _, b := range blocks {
+		if b.width > width {
+			return false
+		}
+	}
+	return true
+}
+
+// sceneBlock is a rendered turn.
+type sceneBlock struct {
+	lines []string
+	width int
+}
+
+// render renders the turn whose balloon is fitted in width.
+func (t turn) render(ctx context.Context, width int) (*sceneBlock, error) {
+	bone := t.bone
+	if width > 0 {
+		fitted, err := bone.Clone(FitWidth(width))
+		if err != nil {
+			return nil, err
+		}
+		bone = fitted
+	}
+	var buf strings.Builder
+	if _, err := bone.Render(ctx, &buf, t.phrase); err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return &sceneBlock{lines: lines, width: maxVisibleWidth(lines)}, nil
+}
+
+// writeTurns writes the blocks from top to bottom. The odd blocks are
+// aligned to the right of the scene.
+func (s *Scene) writeTurns(w *lineWriter, blocks []*sceneBlock) {
+	width := s.maxWidth
+	if width <= 0 {
+		for _, b := range blocks {
+			if b.width > width {
+				width = b.width
+			}
+		}
+	}
+	for i, b := range blocks {
+		if i > 0 {
+			w.WriteRune('\n')
+		}
+		indent := 0
+		if i%2 == 1 && width > b.width {
+			indent = width - b.width
+		}
+		for _, line := range b.lines {
+			if line != "" {
+				writeSpaces(w, indent)
+			}
+			w.WriteString(line)
+			w.WriteRune('\n')
+		}
+	}
+}
+
+// writeSideBySide writes the blocks from left to right. The blocks in a
+// row are aligned to the bottom, and the blocks which do not fit in the
+// width are continued in the next row.
+func (s *Scene) writeSideBySide(w *lineWriter, blocks []*sceneBlock) {
+	for len(blocks) > 0 {
+		n, width := 1, blocks[0].width
+		for n < len(blocks) && (s.maxWidth <= 0 || width+sceneGap+blocks[n].width <= s.maxWidth) {
+			width += sceneGap + blocks[n].width
+			n++
+		}
+		writeRow(w, blocks[:n])
+		blocks = blocks[n:]
+		if len(blocks) > 0 {
+			w.WriteRune('\n')
+		}
+	}
+}
+
+// writeRow writes the blocks side by side which are aligned to the bottom.
+func writeRow(w *lineWriter, blocks []*sceneBlock) {
+	height := 0
+	for _, b := range blocks {
+		if len(b.lines) > height {
+			height = len(b.lines)
+		}
+	}
+	var line strings.Builder
+	for i := 0; i < height; i++ {
+		line.Reset()
+		for j, b := range blocks {
+			if j > 0 {
+				line.WriteString(strings.Repeat(" ", sceneGap))
+			}
+			var text string
+			if k := i - (height - len(b.lines)); k >= 0 {
+				text = b.lines[k]
+			}
+			line.WriteString(text)
+			if pad := b.width - visibleWidth(text); pad > 0 {
+				line.WriteString(strings.Repeat(" ", pad))
+			}
+		}
+		w.WriteString(strings.TrimRight(line.String(), " "))
+		w.WriteRune('\n')
+	}
+}
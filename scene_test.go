@@ -0,0 +1,100 @@
+package bonesay
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestScene(t *testing.T) {
+	a, err := New(FromString(" $thoughts\n($eyes)"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := a.Clone(Eyes("^^"), WithBalloonStyle(ASCIIBalloon))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		scene *Scene
+		want  string
+	}{
+		{
+			name:  "turns",
+			scene: NewScene(SceneTurns, 16).Add(a, "hi").Add(b, "hello"),
+			want: "" +
+				" ____\n" +
+				"< hi >\n" +
+				" ----\n" +
+				" /\n" +
+				"(oo)\n" +
+				"\n" +
+				"       +-------+\n" +
+				"       | hello |\n" +
+				"       +-------+\n" +
+				"        /\n" +
+				"       (^^)\n",
+		},
+		{
+			name:  "side by side",
+			scene: NewScene(SceneSideBySide, 0).Add(a, "hi").Add(b, "hello\nworld"),
+			want: "" +
+				"        +-------+\n" +
+				" ____   | hello |\n" +
+				"< hi >  | world |\n" +
+				" ----   +-------+\n" +
+				" /       /\n" +
+				"(oo)    (^^)\n",
+		},
+		{
+			name:  "side by side is wrapped",
+			scene: NewScene(SceneSideBySide, 12).Add(a, "hi").Add(b, "hello"),
+			want: "" +
+				" ____\n" +
+				"< hi >\n" +
+				" ----\n" +
+				" /\n" +
+				"(oo)\n" +
+				"\n" +
+				"+-------+\n" +
+				"| hello |\n" +
+				"+-------+\n" +
+				" /\n" +
+				"(^^)\n",
+		},
+		{
+			name:  "balloons are fitted",
+			scene: NewScene(SceneSideBySide, 42).Add(a, "the quick brown fox").Add(b, "jumps"),
+			want: "" +
+				" _________________\n" +
+				"/ the quick brown \\  +-------+\n" +
+				"\\ fox             /  | jumps |\n" +
+				" -----------------   +-------+\n" +
+				" /                    /\n" +
+				"(oo)                 (^^)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.scene.Say()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want != got {
+				t.Errorf("want\n%s\n-----got\n%s", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var buf strings.Builder
+		_, err := NewScene(SceneTurns, 0).Add(a, "hi").Render(ctx, &buf)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("want %v, but got %v", context.Canceled, err)
+		}
+	})
+}
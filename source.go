@@ -0,0 +1,202 @@
+package bonesay
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// BoneSource is a source of bonefiles.
+//
+// The ".bone" and ".cow" files in the root of the file system are
+// available as bonefiles. e.g. "tux.cow" can be used as Type("tux").
+type BoneSource interface {
+	fs.FS
+
+	// Name returns the name of the source.
+	// It is used as BonePath.Name and BoneFile.BasePath.
+	Name() string
+}
+
+// NewSource returns a BoneSource which reads bonefiles from fsys.
+//
+// fsys may be an embed.FS (use fs.Sub for the sub directory),
+// a *zip.Reader or a fstest.MapFS, for example.
+func NewSource(name string, fsys fs.FS) BoneSource {
+	return &fsSource{name: name, FS: fsys}
+}
+
+type fsSource struct {
+	fs.FS
+	name string
+}
+
+func (s *fsSource) Name() string { return s.name }
+
+// DirSource returns a BoneSource which reads bonefiles in the directory.
+func DirSource(dir string) BoneSource {
+	return dirSource(dir)
+}
+
+type dirSource string
+
+func (d dirSource) Name() string { return string(d) }
+
+func (d dirSource) Open(name string) (fs.File, error) {
+	f, err := os.DirFS(string(d)).Open(name)
+	if perr, ok := err.(*fs.PathError); ok {
+		perr.Path = filepath.Join(string(d), perr.Path)
+	}
+	return f, err
+}
+
+func (d dirSource) Stat(name string) (fs.FileInfo, error) {
+	info, err := fs.Stat(os.DirFS(string(d)), name)
+	if perr, ok := err.(*fs.PathError); ok {
+		perr.Path = filepath.Join(string(d), perr.Path)
+	}
+	return info, err
+}
+
+// BinarySource returns a BoneSource which reads bonefiles in binary.
+func BinarySource() BoneSource {
+	return binarySource{}
+}
+
+type binarySource struct{}
+
+func (binarySource) Name() string { return "bones" }
+
+func (binarySource) Open(name string) (fs.File, error) {
+	return binaryFS.Open(name)
+}
+
+// WithSources specifies the sources to look for bonefiles.
+// They are searched in the specified order by Type and Random, wherever
+// they are specified among the options. It replaces WithWatcher.
+//
+// If this option is not specified, the default search path which is
+// returned by Sources is used: the registered bonefiles, BONEPATH,
+// XDG_DATA_HOME, XDG_DATA_DIRS and the bonefiles in binary.
+func WithSources(sources ...BoneSource) Option {
+	return func(c *Bone) error {
+		c.sources = sources
+		c.watcher = nil
+		return nil
+	}
+}
+
+// BonesIn to get list of bones in the sources.
+//
+// A source which cannot be read does not stop the listing. It is reported
+// by BonePath.Err and has no bonefiles.
+func BonesIn(sources ...BoneSource) ([]*BonePath, error) {
+	bonePaths := make([]*BonePath, 0, len(sources))
+	for _, source := range sources {
+		bonePath, err := readBonePath(source)
+		if err != nil {
+			bonePath = &BonePath{
+				Name:         source.Name(),
+				LocationType: locationOf(source),
+				Source:       source,
+				Err:          err,
+			}
+		}
+		bonePaths = append(bonePaths, bonePath)
+	}
+	return bonePaths, nil
+}
+
+// Resolution describes which bonefile is used for a name.
+type Resolution struct {
+	// BoneFile is the bonefile which is used.
+	BoneFile *BoneFile
+	// Shadowed are the bonefiles of the same name which are hidden by
+	// BoneFile, in order of the search path.
+	Shadowed []*BoneFile
+}
+
+// Resolve looks for the bonefile named name in bonePaths.
+// The first bonefile found in bonePaths wins. See also Sources.
+//
+// If it is not found, it returns *NotFound.
+func Resolve(bonePaths []*BonePath, name string) (*Resolution, error) {
+	var r *Resolution
+	for _, bonePath := range bonePaths {
+		bonefile, ok := bonePath.Lookup(name)
+		if !ok {
+			continue
+		}
+		if r == nil {
+			r = &Resolution{BoneFile: bonefile}
+		} else {
+			r.Shadowed = append(r.Shadowed, bonefile)
+		}
+	}
+	if r == nil {
+		return nil, &NotFound{Bonefile: name}
+	}
+	return r, nil
+}
+
+func readBonePath(source BoneSource) (*BonePath, error) {
+	dirEntries, err := fs.ReadDir(source, ".")
+	if err != nil {
+		return nil, err
+	}
+	path := &BonePath{
+		Name:         source.Name(),
+		BoneFiles:    []string{},
+		LocationType: locationOf(source),
+		Source:       source,
+	}
+	cowfiles := make([]string, 0)
+	for _, entry := range dirEntries {
+		name, format, ok := splitBonefileName(entry.Name())
+		if !ok {
+			continue
+		}
+		switch format {
+		case BoneFormat:
+			path.BoneFiles = append(path.BoneFiles, name)
+		case CowFormat:
+			cowfiles = append(cowfiles, name)
+		}
+	}
+	sort.Strings(path.BoneFiles)
+	for _, name := range cowfiles {
+		if _, ok := path.Lookup(name); !ok {
+			path.CowFiles = append(path.CowFiles, name)
+		}
+	}
+	sort.Strings(path.CowFiles)
+	return path, nil
+}
+
+// splitBonefileName splits the file name into the bonefile name and the
+// format. ok is false if it is not a bonefile.
+func splitBonefileName(filename string) (name string, format Format, ok bool) {
+	for _, format := range []Format{BoneFormat, CowFormat} {
+		if strings.HasSuffix(filename, format.Suffix()) {
+			return strings.TrimSuffix(filename, format.Suffix()), format, true
+		}
+	}
+	return "", 0, false
+}
+
+func locationOf(source BoneSource) LocationType {
+	switch source := source.(type) {
+	case *snapshotSource:
+		return locationOf(source.origin)
+	case binarySource:
+		return InBinary
+	case dirSource:
+		return InDirectory
+	case *memSource:
+		return InMemory
+	default:
+		return InSource
+	}
+}
@@ -0,0 +1,134 @@
+package bonesay
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBonesIn(t *testing.T) {
+	fsys := fstest.MapFS{
+		"hello.bone":  {Data: []byte("$the_bone = <<EOB;\n $thoughts hello\nEOB\n")},
+		"tux.cow":     {Data: []byte("$the_cow = <<EOC;\n $thoughts tux\nEOC\n")},
+		"README.md":   {Data: []byte("not a bonefile")},
+		"dir/ignored": {Data: []byte("not a bonefile")},
+	}
+	source := NewSource("memory", fsys)
+
+	bonePaths, err := BonesIn(source, DirSource("testdata/testdir"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bonePaths) != 2 {
+		t.Fatalf("want 2, but got %d", len(bonePaths))
+	}
+
+	got := bonePaths[0]
+	if got.Source != source {
+		t.Errorf("unexpected source: %v", got.Source)
+	}
+	want := &BonePath{
+		Name:         "memory",
+		BoneFiles:    []string{"hello"},
+		CowFiles:     []string{"tux"},
+		LocationType: InSource,
+		Source:       got.Source,
+	}
+	if diff := cmp.Diff(want, got, cmp.Comparer(func(x, y BoneSource) bool {
+		return x == y
+	})); diff != "" {
+		t.Errorf("(-want, +got)\n%s", diff)
+	}
+
+	if bonePaths[1].LocationType != InDirectory {
+		t.Errorf("want %v, but got %v", InDirectory, bonePaths[1].LocationType)
+	}
+}
+
+func TestWithSources(t *testing.T) {
+	fsys := fstest.MapFS{
+		"hello.bone": {Data: []byte("$the_bone = <<EOB;\n $thoughts hello\nEOB\n")},
+	}
+	sources := WithSources(NewSource("memory", fsys))
+
+	t.Run("type", func(t *testing.T) {
+		bone, err := New(sources, Type("hello"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := bone.GetBone()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := " / hello"; want != got {
+			t.Errorf("want %q, but got %q", want, got)
+		}
+	})
+
+	t.Run("random", func(t *testing.T) {
+		bone, err := New(sources, Random())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bone.typ.Name != "hello" {
+			t.Errorf("want %q, but got %q", "hello", bone.typ.Name)
+		}
+	})
+
+	t.Run("after type", func(t *testing.T) {
+		bone, err := New(Type("hello"), sources)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bone.typ.Name != "hello" {
+			t.Errorf("want %q, but got %q", "hello", bone.typ.Name)
+		}
+		bone, err = New(Random(), sources)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bone.typ.Name != "hello" {
+			t.Errorf("want %q, but got %q", "hello", bone.typ.Name)
+		}
+	})
+
+	t.Run("not in sources", func(t *testing.T) {
+		_, err := New(sources, Type("mobile"))
+		var notfound *NotFound
+		if !errors.As(err, &notfound) {
+			t.Fatalf("want *NotFound, but got %v", err)
+		}
+	})
+}
+
+func TestCowThoughts(t *testing.T) {
+	source := NewSource("memory", fstest.MapFS{
+		"tux.cow": {Data: []byte("$the_cow = <<EOC;\n $thoughts tux\nEOC\n")},
+	})
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{name: "say", want: ` \ tux`},
+		{name: "think", opts: []Option{Thinking()}, want: " o tux"},
+		{name: "thoughts", opts: []Option{Thoughts('*')}, want: " * tux"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bone, err := New(append([]Option{WithSources(source), Type("tux")}, tt.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := bone.GetBone()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want != got {
+				t.Errorf("want %q, but got %q", tt.want, got)
+			}
+		})
+	}
+}
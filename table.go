@@ -0,0 +1,308 @@
+package bonesay
+
+import (
+	"encoding/csv"
+	"strings"
+	"unicode/utf8"
+)
+
+// TableFormat is the format of the table in the phrase.
+type TableFormat int
+
+// TableFormat values.
+const (
+	// TableAuto detects the format. The phrase is TSV if it has a tab, CSV
+	// if every line has the same number of comma-separated fields, and
+	// TableColumns otherwise.
+	TableAuto TableFormat = iota
+	// TableCSV is comma-separated values.
+	TableCSV
+	// TableTSV is tab-separated values.
+	TableTSV
+	// TableColumns is columns which are aligned by spaces, such as the
+	// output of kubectl and docker. The columns are separated where every
+	// line has two or more spaces.
+	TableColumns
+)
+
+func (f TableFormat) String() string {
+	switch f {
+	case TableAuto:
+		return "auto"
+	case TableCSV:
+		return "csv"
+	case TableTSV:
+		return "tsv"
+	case TableColumns:
+		return "columns"
+	}
+	return "unknown"
+}
+
+// Table lays out the phrase as a table whose first row is the header.
+// The columns are separated by the lines which match the balloon style.
+//
+// The widest columns are narrowed so that the table fits in the balloon
+// width, and the cells which are wider than their columns are wrapped.
+// Table takes precedence over Markdown.
+func Table(format TableFormat) Option {
+	return func(c *Bone) error {
+		c.table = true
+		c.tableFormat = format
+		return nil
+	}
+}
+
+// tableRunes are the runes which separate the cells.
+type tableRunes struct {
+	vertical, horizontal, cross rune
+}
+
+// tableRunes returns the runes which match the balloon style.
+func (bone *Bone) tableRunes() tableRunes {
+	if bone.balloonStyle.Bottom >= utf8.RuneSelf {
+		return tableRunes{vertical: '│', horizontal: '─', cross: '┼'}
+	}
+	return tableRunes{vertical: '|', horizontal: '-', cross: '+'}
+}
+
+// tableLines lays out the phrase as a table which fits in width.
+func (bone *Bone) tableLines(phrase string, width int) []string {
+	rows := parseTable(strings.TrimRight(phrase, "\n"), bone.tableFormat, bone.tabWidth)
+	if len(rows) == 0 {
+		return []string{""}
+	}
+	n := 0
+	for _, row := range rows {
+		if len(row) > n {
+			n = len(row)
+		}
+	}
+	widths := make([]int, n)
+	// mins are the widths of the widest clusters, which cannot be wrapped.
+	mins := make([]int, n)
+	for _, row := range rows {
+		for i, cell := range row {
+			if w := visibleWidth(cell); w > widths[i] {
+				widths[i] = w
+			}
+			if w := maxClusterWidth(cell); w > mins[i] {
+				mins[i] = w
+			}
+		}
+	}
+	if !bone.disableWordWrap {
+		// Each separator is a rune between two spaces.
+		fitColumns(widths, mins, width-3*(n-1))
+	}
+
+	runes := bone.tableRunes()
+	separator := " " + string(runes.vertical) + " "
+	lines := make([]string, 0, len(rows)+1)
+	for r, row := range rows {
+		// cells are the lines of each cell which is wrapped in its column.
+		cells := make([][]string, n)
+		height := 1
+		for i := range cells {
+			var cell string
+			if i < len(row) {
+				cell = row[i]
+			}
+			cells[i] = strings.Split(wrapString(cell, widths[i], bone.wrapStrategy), "\n")
+			if len(cells[i]) > height {
+				height = len(cells[i])
+			}
+		}
+		for j := 0; j < height; j++ {
+			var b strings.Builder
+			for i, cell := range cells {
+				if i > 0 {
+					b.WriteString(separator)
+				}
+				var text string
+				if j < len(cell) {
+					text = cell[j]
+				}
+				b.WriteString(text)
+				if pad := widths[i] - visibleWidth(text); pad > 0 {
+					b.WriteString(strings.Repeat(" ", pad))
+				}
+			}
+			lines = append(lines, strings.TrimRight(b.String(), " "))
+		}
+		if r == 0 && len(rows) > 1 {
+			lines = append(lines, headerSeparator(widths, runes))
+		}
+	}
+	return lines
+}
+
+// headerSeparator returns the line under the header.
+func headerSeparator(widths []int, runes tableRunes) string {
+	var b strings.Builder
+	for i, w := range widths {
+		if i > 0 {
+			b.WriteRune(runes.horizontal)
+			b.WriteRune(runes.cross)
+			b.WriteRune(runes.horizontal)
+		}
+		b.WriteString(strings.Repeat(string(runes.horizontal), w))
+	}
+	return b.String()
+}
+
+// fitColumns narrows the widest column one by one until the sum of widths
+// fits in total. A column is not narrowed to less than mins, nor to less
+// than one cell.
+func fitColumns(widths, mins []int, total int) {
+	sum := 0
+	for _, w := range widths {
+		sum += w
+	}
+	for sum > total {
+		widest := -1
+		for i, w := range widths {
+			if w > 1 && w > mins[i] && (widest < 0 || w > widths[widest]) {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			return
+		}
+		widths[widest]--
+		sum--
+	}
+}
+
+// maxClusterWidth returns the width of the widest grapheme cluster in s.
+func maxClusterWidth(s string) int {
+	width := 0
+	eachCluster(s, func(cluster string, escape bool) {
+		if w := clusterWidth(cluster); !escape && w > width {
+			width = w
+		}
+	})
+	return width
+}
+
+// parseTable splits the text into the rows of cells by format.
+func parseTable(text string, format TableFormat, tabWidth int) [][]string {
+	if format == TableAuto {
+		format = detectTableFormat(text)
+	}
+	switch format {
+	case TableCSV:
+		if rows, err := parseDelimited(text, ','); err == nil {
+			return rows
+		}
+	case TableTSV:
+		if rows, err := parseDelimited(text, '\t'); err == nil {
+			return rows
+		}
+	}
+	return parseColumns(expandTabs(text, tabWidth))
+}
+
+// detectTableFormat detects the format of the table in text.
+func detectTableFormat(text string) TableFormat {
+	if strings.Contains(text, "\t") {
+		return TableTSV
+	}
+	rows, err := parseDelimited(text, ',')
+	if err != nil || len(rows) == 0 {
+		return TableColumns
+	}
+	for _, row := range rows {
+		if len(row) < 2 || len(row) != len(rows[0]) {
+			return TableColumns
+		}
+	}
+	return TableCSV
+}
+
+// parseDelimited parses text which is delimited by comma as CSV.
+func parseDelimited(text string, comma rune) ([][]string, error) {
+	r := csv.NewReader(strings.NewReader(text))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			row[i] = strings.TrimSpace(cell)
+		}
+	}
+	return rows, nil
+}
+
+// parseColumns splits each line of text into the cells where every line
+// has two or more spaces.
+func parseColumns(text string) [][]string {
+	lines := strings.Split(text, "\n")
+	// used[x] reports whether the cell at x is not a space in any line.
+	var used []bool
+	for _, line := range lines {
+		x := 0
+		eachCluster(line, func(cluster string, escape bool) {
+			if escape {
+				return
+			}
+			w := clusterWidth(cluster)
+			for len(used) < x+w {
+				used = append(used, false)
+			}
+			if !isSpace(cluster) {
+				for i := x; i < x+w; i++ {
+					used[i] = true
+				}
+			}
+			x += w
+		})
+	}
+	// A single space may be in a cell, e.g. "Up 3 hours".
+	for x := 1; x+1 < len(used); x++ {
+		if !used[x] && used[x-1] && used[x+1] {
+			used[x] = true
+		}
+	}
+	// column[x] is the index of the column which contains the cell at x.
+	// The spaces before the first column are in the first column.
+	column := make([]int, len(used))
+	n := 0
+	for x := range used {
+		if used[x] && (x == 0 || !used[x-1]) {
+			n++
+		}
+		if n > 0 {
+			column[x] = n - 1
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+
+	rows := make([][]string, 0, len(lines))
+	for _, line := range lines {
+		row := make([]strings.Builder, n)
+		x := 0
+		eachCluster(line, func(cluster string, escape bool) {
+			i := n - 1
+			if x < len(column) {
+				i = column[x]
+			}
+			row[i].WriteString(cluster)
+			if !escape {
+				x += clusterWidth(cluster)
+			}
+		})
+		cells := make([]string, n)
+		for i := range row {
+			cells[i] = strings.TrimSpace(row[i].String())
+		}
+		rows = append(rows, cells)
+	}
+	return rows
+}
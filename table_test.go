@@ -0,0 +1,144 @@
+package bonesay
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseTable(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		format TableFormat
+		want   [][]string
+	}{
+		{
+			name:   "csv",
+			text:   "name,city\nalice,\"New York, NY\"",
+			format: TableAuto,
+			want:   [][]string{{"name", "city"}, {"alice", "New York, NY"}},
+		},
+		{
+			name:   "tsv",
+			text:   "name\tcity\nalice\tNew York, NY",
+			format: TableAuto,
+			want:   [][]string{{"name", "city"}, {"alice", "New York, NY"}},
+		},
+		{
+			name:   "columns",
+			text:   "NAME    READY   AGE\nweb-0   1/1     5d\ndb-0            12d",
+			format: TableAuto,
+			want:   [][]string{{"NAME", "READY", "AGE"}, {"web-0", "1/1", "5d"}, {"db-0", "", "12d"}},
+		},
+		{
+			name:   "columns with spaces in cells",
+			text:   "A    B\nx y  z",
+			format: TableColumns,
+			want:   [][]string{{"A", "B"}, {"x y", "z"}},
+		},
+		{
+			name:   "not csv",
+			text:   "a, b\nc",
+			format: TableAuto,
+			want:   [][]string{{"a, b"}, {"c"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseTable(tt.text, tt.format, defaultTabWidth)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("(-want, +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFitColumns(t *testing.T) {
+	widths := []int{10, 3, 6}
+	fitColumns(widths, []int{1, 1, 1}, 12)
+	if diff := cmp.Diff([]int{4, 3, 5}, widths); diff != "" {
+		t.Errorf("(-want, +got)\n%s", diff)
+	}
+	widths = []int{2, 2}
+	fitColumns(widths, []int{1, 1}, 1)
+	if diff := cmp.Diff([]int{1, 1}, widths); diff != "" {
+		t.Errorf("(-want, +got)\n%s", diff)
+	}
+	// A column is not narrowed to less than a double-width character.
+	widths = []int{8, 5}
+	fitColumns(widths, []int{2, 1}, 3)
+	if diff := cmp.Diff([]int{2, 1}, widths); diff != "" {
+		t.Errorf("(-want, +got)\n%s", diff)
+	}
+}
+
+func TestTable(t *testing.T) {
+	const phrase = "name,age,city\nalice,30,New York\nbob,25,Paris\n"
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "classic",
+			opts: []Option{BallonWidth(40)},
+			want: "" +
+				" ________________________ \n" +
+				"/ name  | age | city     \\\n" +
+				"| ------+-----+--------- |\n" +
+				"| alice | 30  | New York |\n" +
+				"\\ bob   | 25  | Paris    /\n" +
+				" ------------------------ \n",
+		},
+		{
+			name: "wrapped cells",
+			opts: []Option{BallonWidth(16), WithBalloonStyle(SingleBalloon)},
+			want: "" +
+				"┌──────────────────┐\n" +
+				"│ nam │ age │ city │\n" +
+				"│ e   │     │      │\n" +
+				"│ ────┼─────┼───── │\n" +
+				"│ ali │ 30  │ New  │\n" +
+				"│ ce  │     │ York │\n" +
+				"│ bob │ 25  │ Pari │\n" +
+				"│     │     │ s    │\n" +
+				"└──────────────────┘\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{FromString(" $thoughts"), Table(TableAuto)}, tt.opts...)
+			bone, err := New(opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := bone.Balloon(phrase); tt.want != got {
+				t.Errorf("want\n%s\n-----got\n%s", tt.want, got)
+			}
+		})
+	}
+	t.Run("double-width characters", func(t *testing.T) {
+		bone, err := New(FromString(" $thoughts"), Table(TableCSV), BallonWidth(5))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "" +
+			" _________ \n" +
+			"/ na | ci \\\n" +
+			"| me | ty |\n" +
+			"| ---+--- |\n" +
+			"| 山 | 東 |\n" +
+			"| 田 | 京 |\n" +
+			"| 太 | 都 |\n" +
+			"| 郎 |    |\n" +
+			"| sm | ne |\n" +
+			"| it | w  |\n" +
+			"| h  | yo |\n" +
+			"\\    | rk /\n" +
+			" --------- \n"
+		if got := bone.Balloon("name,city\n山田太郎,東京都\nsmith,new york"); want != got {
+			t.Errorf("want\n%s\n-----got\n%s", want, got)
+		}
+	})
+}
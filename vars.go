@@ -0,0 +1,161 @@
+package bonesay
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/anthonycuervo23/bonesay/v2/bonefile"
+)
+
+// builtinVars are the variables which are filled by the bone itself.
+// They cannot be declared or overridden by Vars.
+var builtinVars = map[string]bool{
+	"eyes":     true,
+	"tongue":   true,
+	"thoughts": true,
+}
+
+// Vars specifies the values of the variables in the bonefile other than
+// $eyes, $tongue and $thoughts. The values are merged into the ones
+// which are specified before.
+//
+// The bonefile can declare the default value and the width of a variable
+// in the header:
+//
+//	$hat = "^^^";
+//	$hat_width = 5;
+//
+// If the width is declared, the value is padded with spaces or truncated
+// to it, so that the art is kept aligned. Otherwise, the value is used as
+// it is. Rendering fails if the art refers to a variable which has neither
+// a value nor a default.
+func Vars(vars map[string]string) Option {
+	return func(c *Bone) error {
+		// The map is copied because it may be shared with the clones.
+		merged := make(map[string]string, len(c.vars)+len(vars))
+		for name, value := range c.vars {
+			merged[name] = value
+		}
+		for name, value := range vars {
+			merged[name] = value
+		}
+		c.vars = merged
+		return nil
+	}
+}
+
+// templateVar is a variable which is declared in the header of the bonefile.
+type templateVar struct {
+	value string
+	// width is the declared width, or -1 if it is not declared.
+	width int
+}
+
+// parseVars returns the variables which are referred in the art and
+// declared in the header of f.
+func parseVars(f *bonefile.File) (map[string]*templateVar, error) {
+	vars := make(map[string]*templateVar)
+	for _, name := range f.Body.Variables() {
+		if builtinVars[name] {
+			continue
+		}
+		v := &templateVar{width: -1}
+		declared := false
+		if d := f.Directive(name); d != nil {
+			v.value = unquote(d.Value)
+			declared = true
+		}
+		width, err := parseWidth(f, name, -1)
+		if err != nil {
+			return nil, err
+		}
+		if width >= 0 {
+			v.width = width
+			declared = true
+		}
+		if declared {
+			vars[name] = v
+		}
+	}
+	return vars, nil
+}
+
+// parseWidth returns the width of the variable name which is declared by
+// $name_width in the header of f, or def if it is not declared.
+func parseWidth(f *bonefile.File, name string, def int) (int, error) {
+	d := f.Directive(name + "_width")
+	if d == nil {
+		return def, nil
+	}
+	width, err := strconv.Atoi(d.Value)
+	if err != nil || width < 0 {
+		return 0, &bonefile.Error{
+			Filename: f.Name,
+			Pos:      d.ValuePos,
+			Msg:      fmt.Sprintf("invalid $%s_width %q", name, d.Value),
+		}
+	}
+	return width, nil
+}
+
+// unquote removes the quotes around the value of a directive.
+func unquote(s string) string {
+	if n := len(s); n >= 2 && (s[0] == '"' || s[0] == '\'') && s[n-1] == s[0] {
+		return s[1 : n-1]
+	}
+	return s
+}
+
+// expandVars returns the values of the variables in the art. The values
+// by Vars take precedence over the defaults in the bonefile.
+func (bone *Bone) expandVars(declared map[string]*templateVar) map[string]string {
+	vars := make(map[string]string, len(bone.vars)+len(declared)+len(builtinVars))
+	for name, value := range bone.vars {
+		if !builtinVars[name] {
+			vars[name] = value
+		}
+	}
+	for name, v := range declared {
+		value, ok := vars[name]
+		if !ok {
+			value = v.value
+		}
+		if v.width >= 0 {
+			value, _ = adjustToWidth(value, v.width)
+		}
+		vars[name] = value
+	}
+	return vars
+}
+
+// adjustToWidth pads s with spaces or truncates s at a grapheme cluster so
+// that s occupies width cells on the terminal. If a wide character does
+// not fit in the rest of width, it is replaced with spaces and ok is false.
+func adjustToWidth(s string, width int) (ret string, ok bool) {
+	var b strings.Builder
+	n := 0
+	ok = true
+	eachCluster(s, func(cluster string, escape bool) {
+		// Escape sequences are kept even if truncated, so that colors
+		// are reset.
+		if escape {
+			b.WriteString(cluster)
+			return
+		}
+		if n >= width {
+			return
+		}
+		w := clusterWidth(cluster)
+		if n+w > width {
+			b.WriteString(strings.Repeat(" ", width-n))
+			n = width
+			ok = false
+			return
+		}
+		b.WriteString(cluster)
+		n += w
+	})
+	b.WriteString(strings.Repeat(" ", width-n))
+	return b.String(), ok
+}
@@ -0,0 +1,207 @@
+package bonesay
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/anthonycuervo23/bonesay/v2/bonefile"
+)
+
+func TestVars(t *testing.T) {
+	source := NewSource("vars", fstest.MapFS{
+		"hat.bone":   {Data: []byte("$hat = \"^^^\";\n$hat_width = 5;\n$the_bone = <<EOB;\n[${hat}]\n($eyes)\nEOB\n")},
+		"name.bone":  {Data: []byte("$the_bone = <<EOB;\n($eyes) $name\nEOB\n")},
+		"badge.bone": {Data: []byte("$badge = '*';\n$the_bone = <<EOB;\n[$badge]\nEOB\n")},
+	})
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "default",
+			opts: []Option{Type("hat")},
+			want: "[^^^  ]\n(oo)",
+		},
+		{
+			name: "padded",
+			opts: []Option{Type("hat"), Vars(map[string]string{"hat": "ab"})},
+			want: "[ab   ]\n(oo)",
+		},
+		{
+			name: "truncated",
+			opts: []Option{Type("hat"), Vars(map[string]string{"hat": "abcdefg"})},
+			want: "[abcde]\n(oo)",
+		},
+		{
+			name: "wide characters",
+			opts: []Option{Type("hat"), Vars(map[string]string{"hat": "日本語"})},
+			want: "[日本 ]\n(oo)",
+		},
+		{
+			name: "without width",
+			opts: []Option{Type("badge"), Vars(map[string]string{"badge": "VIP"})},
+			want: "[VIP]",
+		},
+		{
+			name: "not declared",
+			opts: []Option{Type("name"), Vars(map[string]string{"name": "bob"})},
+			want: "(oo) bob",
+		},
+		{
+			name: "merged",
+			opts: []Option{
+				Type("hat"),
+				Vars(map[string]string{"hat": "a"}),
+				Vars(map[string]string{"hat": "b", "name": "bob"}),
+			},
+			want: "[b    ]\n(oo)",
+		},
+		{
+			name: "builtins are not overridden",
+			opts: []Option{Type("hat"), Vars(map[string]string{"eyes": "xx"})},
+			want: "[^^^  ]\n(oo)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bone, err := New(append([]Option{WithSources(source)}, tt.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := bone.GetBone()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want != got {
+				t.Errorf("want\n%s\n-----got\n%s", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("clone does not share vars", func(t *testing.T) {
+		bone, err := New(WithSources(source), Type("hat"), Vars(map[string]string{"hat": "a"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := bone.Clone(Vars(map[string]string{"hat": "b"})); err != nil {
+			t.Fatal(err)
+		}
+		got, err := bone.GetBone()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "[a    ]\n(oo)"; want != got {
+			t.Errorf("want\n%s\n-----got\n%s", want, got)
+		}
+	})
+
+	t.Run("invalid width", func(t *testing.T) {
+		source := NewSource("invalid", fstest.MapFS{
+			"width.bone": {Data: []byte("$hat_width = wide;\n$the_bone = <<EOB;\n[$hat]\nEOB\n")},
+		})
+		_, err := Say("hi", WithSources(source), Type("width"))
+		var bfErr *bonefile.Error
+		if !errors.As(err, &bfErr) {
+			t.Errorf("want *bonefile.Error, but got %v", err)
+		}
+	})
+
+	t.Run("undefined", func(t *testing.T) {
+		_, err := Say("hi", WithSources(source), Type("name"))
+		var bfErr *bonefile.Error
+		if !errors.As(err, &bfErr) {
+			t.Errorf("want *bonefile.Error, but got %v", err)
+		}
+	})
+}
+
+func Test_adjustToWidth(t *testing.T) {
+	tests := []struct {
+		name   string
+		s      string
+		width  int
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "empty",
+			s:      "",
+			width:  2,
+			want:   "  ",
+			wantOK: true,
+		},
+		{
+			name:   "1 character",
+			s:      "1",
+			width:  2,
+			want:   "1 ",
+			wantOK: true,
+		},
+		{
+			name:   "2 characters",
+			s:      "12",
+			width:  2,
+			want:   "12",
+			wantOK: true,
+		},
+		{
+			name:   "3 characters",
+			s:      "123",
+			width:  2,
+			want:   "12",
+			wantOK: true,
+		},
+		{
+			name:   "multi-byte",
+			s:      "ôôô",
+			width:  2,
+			want:   "ôô",
+			wantOK: true,
+		},
+		{
+			name:   "emoji",
+			s:      "👀",
+			width:  2,
+			want:   "👀",
+			wantOK: true,
+		},
+		{
+			name:   "emoji sequence",
+			s:      "👁️👁️",
+			width:  4,
+			want:   "👁️👁️",
+			wantOK: true,
+		},
+		{
+			name:   "CJK",
+			s:      "目目",
+			width:  2,
+			want:   "目",
+			wantOK: true,
+		},
+		{
+			name:   "wide character does not fit",
+			s:      "o目",
+			width:  2,
+			want:   "o ",
+			wantOK: false,
+		},
+		{
+			name:   "escape sequence",
+			s:      "\x1b[31m@@\x1b[0m",
+			width:  2,
+			want:   "\x1b[31m@@\x1b[0m",
+			wantOK: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := adjustToWidth(tt.s, tt.width)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("adjustToWidth() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package bonesay
+
+import (
+	"context"
+	"io/fs"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultWatchInterval is the polling interval of Watcher which is used
+// when the interval is not positive.
+const DefaultWatchInterval = 2 * time.Second
+
+// Op is the kind of the change of a bonefile.
+type Op int
+
+// Op values.
+const (
+	Created Op = iota + 1
+	Modified
+	Removed
+)
+
+func (op Op) String() string {
+	switch op {
+	case Created:
+		return "created"
+	case Modified:
+		return "modified"
+	case Removed:
+		return "removed"
+	}
+	return "unknown"
+}
+
+// Event is the change of a bonefile which is found by Watcher.
+type Event struct {
+	Op Op
+	// BoneFile is the changed bonefile. For Removed, it is the bonefile
+	// in the previous index, so it still can be read.
+	BoneFile *BoneFile
+}
+
+// Watcher keeps the index of the bonefiles in the sources in memory and
+// reloads it by polling the sources. It does not depend on cgo or any
+// file system notification.
+//
+// The index holds the contents of the bonefiles, so a bone which is
+// resolved from the index can be rendered even if the bonefile is removed
+// from the disk. The index is replaced atomically, so Type and Random with
+// WithWatcher always see a consistent snapshot.
+//
+// Watcher is safe for concurrent use.
+type Watcher struct {
+	sources  []BoneSource
+	interval time.Duration
+
+	// index is the current []*BonePath.
+	index atomic.Value
+
+	// mu guards snapshots and events, and serializes reloads.
+	mu        sync.Mutex
+	snapshots []*snapshotSource
+
+	events chan Event
+}
+
+// NewWatcher returns the Watcher which polls the sources at the interval.
+// If no sources are specified, the sources returned by Sources are used.
+//
+// The sources are scanned once before NewWatcher returns. Call Run to
+// keep the index up to date.
+func NewWatcher(interval time.Duration, sources ...BoneSource) *Watcher {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	if len(sources) == 0 {
+		sources = Sources()
+	}
+	w := &Watcher{
+		sources:   sources,
+		interval:  interval,
+		snapshots: make([]*snapshotSource, len(sources)),
+	}
+	w.Reload()
+	return w
+}
+
+// WithWatcher specifies the watcher to look for bonefiles.
+// Type and Random use the current index of the watcher instead of
+// scanning the sources, wherever it is specified among the options.
+// It replaces WithSources.
+func WithWatcher(w *Watcher) Option {
+	return func(c *Bone) error {
+		c.watcher = w
+		c.sources = nil
+		return nil
+	}
+}
+
+// Bones returns the current index of the bonefiles.
+// The returned BonePaths must not be modified.
+func (w *Watcher) Bones() []*BonePath {
+	return w.index.Load().([]*BonePath)
+}
+
+// Events returns the channel which receives the changes found by Run.
+// Once Events is called, Run blocks until each event is received,
+// so the channel must be drained.
+func (w *Watcher) Events() <-chan Event {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.events == nil {
+		w.events = make(chan Event, 64)
+	}
+	return w.events
+}
+
+// Run polls the sources until ctx is done, and returns ctx.Err().
+func (w *Watcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		events := w.Reload()
+		w.mu.Lock()
+		ch := w.events
+		w.mu.Unlock()
+		if ch == nil {
+			continue
+		}
+		for _, ev := range events {
+			select {
+			case ch <- ev:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// Reload scans the sources immediately and returns the changes since the
+// last scan. A source which cannot be read is reported by BonePath.Err
+// and all bonefiles in it are removed from the index.
+func (w *Watcher) Reload() []Event {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var events []Event
+	bonePaths := make([]*BonePath, 0, len(w.sources))
+	for i, source := range w.sources {
+		snap, changes, err := scanSource(source, w.snapshots[i])
+		events = append(events, changes...)
+		w.snapshots[i] = snap
+		if err != nil {
+			bonePaths = append(bonePaths, &BonePath{
+				Name:         source.Name(),
+				LocationType: locationOf(source),
+				Source:       source,
+				Err:          err,
+			})
+			continue
+		}
+		bonePath, err := readBonePath(snap)
+		if err != nil {
+			// unreachable: snapshots are always readable.
+			panic(err)
+		}
+		bonePaths = append(bonePaths, bonePath)
+	}
+	w.index.Store(bonePaths)
+	return events
+}
+
+// snapshotSource is the contents of a source at a scan.
+// It is not modified after the scan.
+type snapshotSource struct {
+	*memSource
+	origin BoneSource
+}
+
+func (s *snapshotSource) Name() string { return s.origin.Name() }
+
+func (s *snapshotSource) boneFile(name string, format Format) *BoneFile {
+	return &BoneFile{
+		Name:         name,
+		BasePath:     s.Name(),
+		LocationType: locationOf(s.origin),
+		Source:       s,
+		Format:       format,
+	}
+}
+
+// scanSource reads the bonefiles in source which are added or modified
+// since old. If nothing is changed, old is returned as it is, so the
+// cached templates of old are still used.
+func scanSource(source BoneSource, old *snapshotSource) (*snapshotSource, []Event, error) {
+	var prev map[string]*memFileInfo
+	if old != nil {
+		prev = old.files
+	}
+	entries, err := fs.ReadDir(source, ".")
+	if err != nil {
+		return nil, removedEvents(old, prev, nil), err
+	}
+
+	snap := &snapshotSource{
+		memSource: newMemSource(source.Name()),
+		origin:    source,
+	}
+	var events []Event
+	for _, entry := range entries {
+		name, format, ok := splitBonefileName(entry.Name())
+		if !ok || entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// removed while scanning
+			continue
+		}
+		p, ok := prev[entry.Name()]
+		if ok && p.modTime.Equal(info.ModTime()) && p.Size() == info.Size() {
+			snap.files[entry.Name()] = p
+			continue
+		}
+		data, err := fs.ReadFile(source, entry.Name())
+		if err != nil {
+			if ok {
+				snap.files[entry.Name()] = p
+			}
+			continue
+		}
+		snap.files[entry.Name()] = &memFileInfo{
+			name:    entry.Name(),
+			data:    data,
+			modTime: info.ModTime(),
+		}
+		op := Created
+		if ok {
+			op = Modified
+		}
+		events = append(events, Event{Op: op, BoneFile: snap.boneFile(name, format)})
+	}
+	events = append(events, removedEvents(old, prev, snap.files)...)
+	if old != nil && len(events) == 0 {
+		return old, nil, nil
+	}
+	return snap, events, nil
+}
+
+// removedEvents returns the events of the files which are in prev but
+// not in current.
+func removedEvents(old *snapshotSource, prev, current map[string]*memFileInfo) []Event {
+	var removed []string
+	for filename := range prev {
+		if _, ok := current[filename]; !ok {
+			removed = append(removed, filename)
+		}
+	}
+	sort.Strings(removed)
+	events := make([]Event, 0, len(removed))
+	for _, filename := range removed {
+		name, format, _ := splitBonefileName(filename)
+		events = append(events, Event{Op: Removed, BoneFile: old.boneFile(name, format)})
+	}
+	return events
+}
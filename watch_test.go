@@ -0,0 +1,130 @@
+package bonesay
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	writeBone := func(name, art string, modTime time.Time) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("$the_bone = <<EOB;\n"+art+"\nEOB\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	writeBone("a.bone", "a($eyes)", modTime)
+
+	w := NewWatcher(time.Hour, DirSource(dir))
+	bone, err := New(WithWatcher(w), Type("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("initial", func(t *testing.T) {
+		bonePaths := w.Bones()
+		if len(bonePaths) != 1 {
+			t.Fatalf("want 1, but got %d", len(bonePaths))
+		}
+		if got := bonePaths[0]; got.LocationType != InDirectory || got.Name != dir {
+			t.Errorf("want %s in directory, but got %s %v", dir, got.Name, got.LocationType)
+		}
+		if events := w.Reload(); len(events) != 0 {
+			t.Errorf("want no events, but got %v", events)
+		}
+	})
+
+	t.Run("changes", func(t *testing.T) {
+		writeBone("b.bone", "b($eyes)", modTime)
+		writeBone("a.bone", "A($eyes)", modTime.Add(time.Second))
+		events := w.Reload()
+		want := []struct {
+			op   Op
+			name string
+		}{{Modified, "a"}, {Created, "b"}}
+		if len(events) != len(want) {
+			t.Fatalf("want %d events, but got %v", len(want), events)
+		}
+		for i, ev := range events {
+			if ev.Op != want[i].op || ev.BoneFile.Name != want[i].name {
+				t.Errorf("want %v %s, but got %v %s", want[i].op, want[i].name, ev.Op, ev.BoneFile.Name)
+			}
+		}
+		got, err := Say("", WithWatcher(w), Type("a"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "A(oo)"; got[len(got)-len(want):] != want {
+			t.Errorf("want %q at the end, but got %q", want, got)
+		}
+	})
+
+	t.Run("removed", func(t *testing.T) {
+		if err := os.Remove(filepath.Join(dir, "a.bone")); err != nil {
+			t.Fatal(err)
+		}
+		events := w.Reload()
+		if len(events) != 1 || events[0].Op != Removed || events[0].BoneFile.Name != "a" {
+			t.Fatalf("want a removed, but got %v", events)
+		}
+		// The bone which was resolved before still can be rendered.
+		got, err := bone.GetBone()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "a(oo)"; want != got {
+			t.Errorf("want %q, but got %q", want, got)
+		}
+		var notFound *NotFound
+		if _, err := New(WithWatcher(w), Type("a")); !errors.As(err, &notFound) {
+			t.Errorf("want *NotFound, but got %v", err)
+		}
+	})
+
+	t.Run("unreadable", func(t *testing.T) {
+		w := NewWatcher(time.Hour, DirSource(filepath.Join(dir, "missing")))
+		bonePaths := w.Bones()
+		if len(bonePaths) != 1 || bonePaths[0].Err == nil {
+			t.Errorf("want error, but got %v", bonePaths)
+		}
+	})
+}
+
+func TestWatcher_Run(t *testing.T) {
+	dir := t.TempDir()
+	w := NewWatcher(time.Millisecond, DirSource(dir))
+	events := w.Events()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx) }()
+
+	if err := os.WriteFile(filepath.Join(dir, "new.bone"), []byte("$the_bone = <<EOB;\nnew\nEOB\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case ev := <-events:
+		if ev.Op != Created || ev.BoneFile.Name != "new" {
+			t.Errorf("want new created, but got %v %s", ev.Op, ev.BoneFile.Name)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout")
+	}
+	if _, err := New(WithWatcher(w), Type("new")); err != nil {
+		t.Error(err)
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("want %v, but got %v", context.Canceled, err)
+	}
+}
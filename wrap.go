@@ -0,0 +1,278 @@
+package bonesay
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WrapStrategy is how a word which is longer than the balloon width is
+// broken. Words are wrapped at white spaces in any strategy.
+type WrapStrategy int
+
+// WrapStrategy values.
+const (
+	// WrapHard breaks a long word at the balloon width. It is the default.
+	WrapHard WrapStrategy = iota
+	// WrapPunctuation breaks a long word after the last "/", "-", "_" or
+	// "." which fits in the line, e.g. URLs and identifiers. If there is
+	// no such punctuation, the word is broken at the balloon width.
+	WrapPunctuation
+	// WrapHyphenate breaks a long word between letters and appends "-" to
+	// the line. The break is preferred between a vowel and a consonant,
+	// and at least two letters are left on each side. It does not use any
+	// dictionary. Punctuation is used as WrapPunctuation.
+	WrapHyphenate
+)
+
+func (s WrapStrategy) String() string {
+	switch s {
+	case WrapHard:
+		return "hard"
+	case WrapPunctuation:
+		return "punctuation"
+	case WrapHyphenate:
+		return "hyphenate"
+	}
+	return "unknown"
+}
+
+// WithWrapStrategy specifies how a word which is longer than the balloon
+// width is broken.
+func WithWrapStrategy(s WrapStrategy) Option {
+	return func(c *Bone) error {
+		c.wrapStrategy = s
+		return nil
+	}
+}
+
+// piece is a grapheme cluster or an escape sequence in a word.
+type piece struct {
+	text   string
+	width  int
+	escape bool
+}
+
+type word []piece
+
+func (w word) width() int {
+	width := 0
+	for _, p := range w {
+		width += p.width
+	}
+	return width
+}
+
+func (w word) String() string {
+	var b strings.Builder
+	for _, p := range w {
+		b.WriteString(p.text)
+	}
+	return b.String()
+}
+
+// wrapString wraps s at white spaces so that each line fits in limit
+// cells. A word which is longer than limit is broken by strategy between
+// grapheme clusters. A grapheme cluster which is wider than limit is not
+// broken, so the line may be wider than limit.
+//
+// It is based on github.com/Code-Hex/go-wordwrap. Escape sequences are
+// kept in the words as they are, and they occupy no cells.
+func wrapString(s string, limit int, strategy WrapStrategy) string {
+	var buf strings.Builder
+	buf.Grow(len(s))
+
+	// word and space are buffered with their widths.
+	var (
+		w          word
+		wordWidth  int
+		space      strings.Builder
+		spaceWidth int
+	)
+	flushSpace := func() {
+		buf.WriteString(space.String())
+		space.Reset()
+		spaceWidth = 0
+	}
+	resetSpace := func() {
+		space.Reset()
+		spaceWidth = 0
+	}
+	flushWord := func() {
+		buf.WriteString(w.String())
+		w, wordWidth = w[:0], 0
+	}
+
+	var current int
+	eachCluster(s, func(cluster string, escape bool) {
+		if escape {
+			w = append(w, piece{text: cluster, escape: true})
+			return
+		}
+
+		if cluster == "\n" {
+			if len(w) == 0 {
+				if current+spaceWidth <= limit {
+					flushSpace()
+				}
+				resetSpace()
+			} else {
+				flushSpace()
+				flushWord()
+			}
+			buf.WriteString(cluster)
+			current = 0
+			return
+		}
+
+		l := clusterWidth(cluster)
+		if isSpace(cluster) {
+			if spaceWidth == 0 || wordWidth > 0 {
+				current += spaceWidth + wordWidth
+				flushSpace()
+				flushWord()
+			}
+			space.WriteString(cluster)
+			spaceWidth += l
+			return
+		}
+
+		for current+wordWidth+l > limit && (current > 0 || wordWidth > 0) {
+			head, tail, hyphen := breakWord(w, cluster, limit-current, strategy)
+			buf.WriteString(head.String())
+			if hyphen {
+				buf.WriteByte('-')
+			}
+			buf.WriteByte('\n')
+			current = 0
+			resetSpace()
+			w, wordWidth = append(word(nil), tail...), tail.width()
+		}
+		if current+spaceWidth+wordWidth+l > limit && wordWidth+l < limit {
+			buf.WriteByte('\n')
+			current = 0
+			resetSpace()
+		}
+		w = append(w, piece{text: cluster, width: l})
+		wordWidth += l
+	})
+
+	if len(w) == 0 {
+		if current+spaceWidth <= limit {
+			flushSpace()
+		}
+	} else {
+		flushSpace()
+		flushWord()
+	}
+	return buf.String()
+}
+
+// breakWord breaks w which is followed by next, so that head fits in room
+// cells. tail is continued to the next line. If hyphen is true, "-" is
+// appended to head.
+func breakWord(w word, next string, room int, strategy WrapStrategy) (head, tail word, hyphen bool) {
+	// The escape sequences before the word are continued with the word.
+	if w.width() == 0 {
+		return nil, w, false
+	}
+	switch strategy {
+	case WrapPunctuation:
+		if i := lastPunctuation(w); i >= 0 {
+			return w[:i+1], w[i+1:], false
+		}
+	case WrapHyphenate:
+		if i := lastPunctuation(w); i >= 0 {
+			return w[:i+1], w[i+1:], false
+		}
+		if i := hyphenationPoint(w, next, room); i > 0 {
+			return w[:i], w[i:], true
+		}
+	}
+	return w, nil, false
+}
+
+// lastPunctuation returns the index of the last punctuation at which w can
+// be broken. It returns -1 if there is no such punctuation.
+func lastPunctuation(w word) int {
+	// The last piece is not a break point, since nothing would be left.
+	for i := len(w) - 2; i >= 0; i-- {
+		if !w[i].escape && strings.Contains("/-_.", w[i].text) {
+			return i
+		}
+	}
+	return -1
+}
+
+// hyphenationPoint returns the index of w at which w is broken with
+// a hyphen, so that w[:i] and "-" fit in room cells. It returns 0 if w
+// cannot be hyphenated.
+func hyphenationPoint(w word, next string, room int) int {
+	const (
+		// minLetters is the number of letters which are left on each side.
+		minLetters = 2
+		// lookBack is how far the break between a vowel and a consonant
+		// is looked for from the last break point.
+		lookBack = 3
+	)
+	total := 0
+	for _, p := range w {
+		if isLetter(p.text) {
+			total++
+		}
+	}
+	if isLetter(next) {
+		total++
+	}
+	// following returns the text of the cluster after w[:i].
+	following := func(i int) string {
+		if i < len(w) {
+			return w[i].text
+		}
+		return next
+	}
+
+	var candidates []int
+	width, letters := 0, 0
+	for i := 1; i <= len(w); i++ {
+		p := w[i-1]
+		width += p.width
+		if width+1 > room {
+			break
+		}
+		if !isLetter(p.text) {
+			continue
+		}
+		letters++
+		if letters >= minLetters && total-letters >= minLetters && isLetter(following(i)) {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return 0
+	}
+	last := candidates[len(candidates)-1]
+	for j := len(candidates) - 1; j >= 0 && last-candidates[j] <= lookBack; j-- {
+		i := candidates[j]
+		if isVowel(w[i-1].text) && !isVowel(following(i)) {
+			return i
+		}
+	}
+	return last
+}
+
+func isLetter(cluster string) bool {
+	r, _ := utf8.DecodeRuneInString(cluster)
+	return unicode.IsLetter(r)
+}
+
+func isVowel(cluster string) bool {
+	r, _ := utf8.DecodeRuneInString(cluster)
+	return strings.ContainsRune("aeiouyAEIOUY", r)
+}
+
+// isSpace reports whether the grapheme cluster is a white space.
+func isSpace(cluster string) bool {
+	r, size := utf8.DecodeRuneInString(cluster)
+	return size == len(cluster) && unicode.IsSpace(r)
+}
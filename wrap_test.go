@@ -0,0 +1,155 @@
+package bonesay
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWrapString(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		limit int
+		want  string
+	}{
+		{
+			name:  "words",
+			s:     "the quick brown fox",
+			limit: 10,
+			want:  "the quick\nbrown fox",
+		},
+		{
+			name:  "long word",
+			s:     "abcdefghijkl",
+			limit: 5,
+			want:  "abcde\nfghij\nkl",
+		},
+		{
+			name:  "newline",
+			s:     "foo\nbar baz",
+			limit: 5,
+			want:  "foo\nbar\nbaz",
+		},
+		{
+			name:  "wide characters",
+			s:     "こんにちは 世界",
+			limit: 10,
+			want:  "こんにちは\n世界",
+		},
+		{
+			name:  "escape sequences occupy no cells",
+			s:     "\x1b[31mthe quick\x1b[0m brown",
+			limit: 9,
+			want:  "\x1b[31mthe quick\x1b[0m\nbrown",
+		},
+		{
+			name:  "escape sequences are not broken",
+			s:     "\x1b[38;5;82mabcdef\x1b[0m",
+			limit: 3,
+			want:  "\x1b[38;5;82mabc\ndef\x1b[0m",
+		},
+		{
+			name:  "escape sequences are continued with the word",
+			s:     "ab \x1b[1mcd\x1b[0m",
+			limit: 3,
+			want:  "ab\n\x1b[1mcd\x1b[0m",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapString(tt.s, tt.limit, WrapHard); tt.want != got {
+				t.Errorf("want %q, but got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWrapString_Strategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		limit    int
+		strategy WrapStrategy
+		want     string
+	}{
+		{
+			name:     "hard",
+			s:        "see https://example.com/foo-bar",
+			limit:    10,
+			strategy: WrapHard,
+			want:     "see\nhttps://ex\nample.com/\nfoo-bar",
+		},
+		{
+			name:     "punctuation",
+			s:        "see https://example.com/foo-bar",
+			limit:    10,
+			strategy: WrapPunctuation,
+			want:     "see\nhttps://\nexample.\ncom/foo-\nbar",
+		},
+		{
+			name:     "punctuation without punctuation",
+			s:        "abcdefghijkl",
+			limit:    5,
+			strategy: WrapPunctuation,
+			want:     "abcde\nfghij\nkl",
+		},
+		{
+			name:     "hyphenate",
+			s:        "internationalization",
+			limit:    8,
+			strategy: WrapHyphenate,
+			want:     "interna-\ntionali-\nzation",
+		},
+		{
+			name:     "hyphenate leaves two letters",
+			s:        "abcde",
+			limit:    4,
+			strategy: WrapHyphenate,
+			want:     "abc-\nde",
+		},
+		{
+			name:     "hyphenate prefers punctuation",
+			s:        "snake_case_name",
+			limit:    12,
+			strategy: WrapHyphenate,
+			want:     "snake_case_\nname",
+		},
+		{
+			name:     "cluster wider than limit",
+			s:        "漢字",
+			limit:    1,
+			strategy: WrapHyphenate,
+			want:     "漢\n字",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapString(tt.s, tt.limit, tt.strategy); tt.want != got {
+				t.Errorf("want %q, but got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBalloon_EnclosesText(t *testing.T) {
+	for _, strategy := range []WrapStrategy{WrapHard, WrapPunctuation, WrapHyphenate} {
+		t.Run(strategy.String(), func(t *testing.T) {
+			bone, err := New(
+				FromString(" $thoughts"),
+				BallonWidth(1),
+				WithWrapStrategy(strategy),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := bone.Balloon("漢字 https://example.com")
+			lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+			width := visibleWidth(lines[0])
+			for _, line := range lines {
+				if w := visibleWidth(line); w != width {
+					t.Fatalf("want all lines %d wide, but got %d:\n%s", width, w, got)
+				}
+			}
+		})
+	}
+}